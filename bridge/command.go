@@ -0,0 +1,145 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"moonraker2mqtt/metrics"
+	"moonraker2mqtt/moonraker"
+)
+
+func (p *Printer) SubscribeCommands() {
+	if p.currentMQTTConfig().EstopEnabled {
+		estopTopic := p.estopTopic()
+		if err := p.mqttClient.Subscribe(estopTopic, p.handleEstop); err != nil {
+			p.logger.Warn("Failed to subscribe to emergency stop topic %s: %v", estopTopic, err)
+		} else {
+			p.logger.Info("Subscribed to emergency stop topic: %s", estopTopic)
+		}
+	}
+
+	if !p.currentMQTTConfig().CommandsEnabled {
+		return
+	}
+
+	commandTopic := p.commandTopic()
+	if err := p.mqttClient.Subscribe(commandTopic, p.handleCommand); err != nil {
+		p.logger.Warn("Failed to subscribe to command topic %s: %v", commandTopic, err)
+	} else {
+		p.logger.Info("Subscribed to command topic: %s", commandTopic)
+	}
+}
+
+func (p *Printer) UnsubscribeCommands() {
+	if p.currentMQTTConfig().EstopEnabled {
+		if err := p.mqttClient.Unsubscribe(p.estopTopic()); err != nil {
+			p.logger.Warn("Failed to unsubscribe from emergency stop topic: %v", err)
+		}
+	}
+
+	if p.currentMQTTConfig().CommandsEnabled {
+		if err := p.mqttClient.Unsubscribe(p.commandTopic()); err != nil {
+			p.logger.Warn("Failed to unsubscribe from command topic: %v", err)
+		}
+	}
+}
+
+func (p *Printer) estopTopic() string {
+	return fmt.Sprintf("%s/estop", p.TopicPrefix())
+}
+
+func (p *Printer) commandTopic() string {
+	return fmt.Sprintf("%s/commands", p.TopicPrefix())
+}
+
+func (p *Printer) commandResultTopic() string {
+	return fmt.Sprintf("%s/commands/result", p.TopicPrefix())
+}
+
+func (p *Printer) handleEstop(topic string, payload []byte) {
+	p.logger.Warn("Emergency stop requested on %s", topic)
+
+	go func() {
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), p.currentConfig().GetTimeout())
+		defer cancel()
+
+		err := p.client.EmergencyStop(ctx)
+		result := &moonraker.CommandResult{
+			Command:    "emergency_stop",
+			Status:     moonraker.COMMAND_STATUS_SUCCESS,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			p.logger.Error("Emergency stop failed: %v", err)
+			result.Status = moonraker.COMMAND_STATUS_ERROR
+			result.Error = moonraker.ToCommandError(err)
+		}
+
+		p.publishCommandResult(result)
+	}()
+}
+
+func (p *Printer) handleCommand(topic string, payload []byte) {
+	p.logger.Info("Received command on topic: %s", topic)
+
+	var cmdMsg moonraker.CommandMessage
+	if err := json.Unmarshal(payload, &cmdMsg); err == nil && moonraker.IsPriorityCommand(cmdMsg.Command) {
+		go func() {
+			p.publishCommandResult(p.client.HandleCommand(context.Background(), payload))
+		}()
+		return
+	}
+
+	select {
+	case p.commands <- commandRequest{topic: topic, payload: payload}:
+	default:
+		p.logger.Warn("Command queue full, rejecting command from %s", topic)
+
+		go p.publishCommandResult(&moonraker.CommandResult{
+			ID:      cmdMsg.ID,
+			Command: cmdMsg.Command,
+			Status:  moonraker.COMMAND_STATUS_ERROR,
+			Error:   moonraker.NewCommandError(moonraker.COMMAND_ERROR_INTERNAL, "command queue full"),
+			ReplyTo: cmdMsg.ReplyTo,
+		})
+	}
+}
+
+func (p *Printer) processCommands(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case request := <-p.commands:
+			result := p.client.HandleCommand(ctx, request.payload)
+			p.publishCommandResult(result)
+		}
+	}
+}
+
+func (p *Printer) publishCommandResult(result *moonraker.CommandResult) {
+	metrics.Commands.Inc(result.Command, result.Status)
+
+	topic := p.commandResultTopic()
+	if result.ReplyTo != "" {
+		if strings.ContainsAny(result.ReplyTo, "+#") {
+			p.logger.Warn("Ignoring invalid reply topic %s for command %s", result.ReplyTo, result.Command)
+		} else {
+			topic = result.ReplyTo
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		p.logger.Error("Failed to marshal command result: %v", err)
+		return
+	}
+
+	if err := p.mqttClient.Publish(topic, data, p.currentMQTTConfig().QoS, false, 3); err != nil {
+		p.logger.Error("Failed to publish command result to %s: %v", topic, err)
+	}
+}
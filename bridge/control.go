@@ -0,0 +1,292 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/mqtt"
+)
+
+func BridgeControlTopic(topicPrefix string) string {
+	return fmt.Sprintf("%s/bridge/set", topicPrefix)
+}
+
+func BridgeConfigTopic(topicPrefix string) string {
+	return fmt.Sprintf("%s/bridge/config", topicPrefix)
+}
+
+func NewController(printers []*Printer, cfg *config.Config, mqttClient mqtt.MQTTClient, root logger.Logger) *Controller {
+	return &Controller{
+		printers:      printers,
+		mqttClient:    mqttClient,
+		topicPrefix:   cfg.MQTT.TopicPrefix,
+		qos:           cfg.MQTT.QoS,
+		logComponents: logComponentLevels(cfg.Logging.Components),
+		root:          root,
+		logger:        root.WithComponent(LOG_COMPONENT),
+	}
+}
+
+func (c *Controller) Reload(cfg *config.Config) {
+	c.mux.Lock()
+	previous := c.topicPrefix
+	c.topicPrefix = cfg.MQTT.TopicPrefix
+	c.qos = cfg.MQTT.QoS
+	c.logComponents = logComponentLevels(cfg.Logging.Components)
+	c.mux.Unlock()
+
+	if previous == cfg.MQTT.TopicPrefix {
+		return
+	}
+
+	if err := c.mqttClient.Unsubscribe(BridgeControlTopic(previous)); err != nil {
+		c.logger.Warn("Failed to unsubscribe from bridge control topic: %v", err)
+	}
+	if err := c.Subscribe(); err != nil {
+		c.logger.Warn("Failed to subscribe to bridge control topic: %v", err)
+	}
+}
+
+func (c *Controller) Subscribe() error {
+	topic := BridgeControlTopic(c.prefix())
+	if err := c.mqttClient.Subscribe(topic, c.handleControl); err != nil {
+		return err
+	}
+
+	c.logger.Info("Subscribed to bridge control topic: %s", topic)
+	return nil
+}
+
+func (c *Controller) handleControl(topic string, payload []byte) {
+	c.logger.Info("Received bridge control request on topic: %s", topic)
+
+	var request ControlRequest
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		c.logger.Warn("Ignoring malformed bridge control request: %v", err)
+	} else if err := c.Apply(request); err != nil {
+		c.logger.Warn("Rejected bridge control request: %v", err)
+	}
+
+	if err := c.PublishConfig(); err != nil {
+		c.logger.Warn("Failed to publish bridge config: %v", err)
+	}
+}
+
+func (c *Controller) Apply(request ControlRequest) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	printers, err := c.targets(request.Printer)
+	if err != nil {
+		return err
+	}
+
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	if request.LogLevel != "" {
+		c.root.SetLevel(logger.ParseLogLevel(request.LogLevel))
+		c.logger.Info("Log level set to %s", strings.ToLower(request.LogLevel))
+	}
+
+	for component, level := range request.LogComponents {
+		c.root.WithComponent(component).SetLevel(logger.ParseLogLevel(level))
+		c.logComponents[component] = strings.ToLower(level)
+		c.logger.Info("Log level of component %s set to %s", component, strings.ToLower(level))
+	}
+
+	for _, printer := range printers {
+		if request.CallInterval != nil {
+			printer.SetCallInterval(time.Duration(*request.CallInterval) * time.Second)
+		}
+
+		if len(request.AddObjects) > 0 || len(request.RemoveObjects) > 0 {
+			objects := printer.MonitoredObjects()
+			maps.Copy(objects, request.AddObjects)
+			for _, objectName := range request.RemoveObjects {
+				delete(objects, objectName)
+			}
+			printer.SetMonitoredObjects(objects)
+		}
+
+		if request.Paused != nil {
+			printer.SetPaused(*request.Paused)
+		}
+
+		if request.Republish {
+			printer.Republish()
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) Config() BridgeConfig {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	bridgeConfig := BridgeConfig{
+		LogLevel:      strings.ToLower(c.root.GetLevel().String()),
+		LogComponents: maps.Clone(c.logComponents),
+		Printers:      make([]PrinterSettings, 0, len(c.printers)),
+	}
+
+	for _, printer := range c.printers {
+		bridgeConfig.Printers = append(bridgeConfig.Printers, printer.Settings())
+	}
+
+	return bridgeConfig
+}
+
+func (c *Controller) PublishConfig() error {
+	payload, err := json.Marshal(c.Config())
+	if err != nil {
+		return fmt.Errorf("failed to marshal bridge config: %w", err)
+	}
+
+	c.mux.Lock()
+	topicPrefix, qos := c.topicPrefix, c.qos
+	c.mux.Unlock()
+
+	return c.mqttClient.Publish(BridgeConfigTopic(topicPrefix), payload, qos, true, 1)
+}
+
+func (c *Controller) prefix() string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.topicPrefix
+}
+
+func (c *Controller) targets(name string) ([]*Printer, error) {
+	if name == "" {
+		return c.printers, nil
+	}
+
+	for _, printer := range c.printers {
+		if printer.Name() == name {
+			return []*Printer{printer}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown printer %q", name)
+}
+
+func logComponentLevels(components map[string]string) map[string]string {
+	levels := make(map[string]string, len(components))
+	for component, level := range components {
+		levels[component] = strings.ToLower(level)
+	}
+	return levels
+}
+
+func (r *ControlRequest) Validate() error {
+	if r.LogLevel != "" && !config.IsValidLogLevel(r.LogLevel) {
+		return fmt.Errorf("invalid log level %q", r.LogLevel)
+	}
+
+	for component, level := range r.LogComponents {
+		if !config.IsValidLogComponent(component) {
+			return fmt.Errorf("unknown log component %q", component)
+		}
+		if !config.IsValidLogLevel(level) {
+			return fmt.Errorf("invalid log level %q for component %s", level, component)
+		}
+	}
+
+	if r.CallInterval != nil && *r.CallInterval <= 0 {
+		return fmt.Errorf("call interval must be positive, got %d", *r.CallInterval)
+	}
+
+	if err := config.ValidateMonitoredObjects(r.AddObjects); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *Printer) CallInterval() time.Duration {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.callInterval
+}
+
+func (p *Printer) SetCallInterval(interval time.Duration) {
+	p.settingsMux.Lock()
+	changed := interval != p.callInterval
+	p.callInterval = interval
+	p.settingsMux.Unlock()
+
+	if !changed {
+		return
+	}
+
+	p.logger.Info("Call interval set to %s", interval)
+
+	select {
+	case p.intervalChanged <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Printer) MonitoredObjects() map[string]any {
+	return p.monitoredObjects()
+}
+
+func (p *Printer) SetMonitoredObjects(objects map[string]any) {
+	p.settingsMux.Lock()
+	p.monitored = maps.Clone(objects)
+	p.settingsMux.Unlock()
+
+	p.logger.Info("Monitoring %d object(s)", len(objects))
+	p.Republish()
+}
+
+func (p *Printer) Paused() bool {
+	return p.paused.Load()
+}
+
+func (p *Printer) SetPaused(paused bool) {
+	if p.paused.Swap(paused) == paused {
+		return
+	}
+
+	if paused {
+		p.logger.Info("Publishing paused")
+		return
+	}
+
+	p.logger.Info("Publishing resumed")
+	if p.mqttClient.IsConnected() {
+		p.publishCurrentJob()
+		if !p.client.IsConnected() {
+			p.publishAvailability(false)
+		}
+	}
+	p.Republish()
+}
+
+func (p *Printer) Republish() {
+	if !p.started.Load() {
+		return
+	}
+
+	go p.resync(p.ctx)
+}
+
+func (p *Printer) Settings() PrinterSettings {
+	return PrinterSettings{
+		Name:             p.Name(),
+		CallInterval:     int(p.CallInterval() / time.Second),
+		MonitoredObjects: p.monitoredObjects(),
+		Paused:           p.Paused(),
+	}
+}
@@ -0,0 +1,212 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/moonraker"
+	"moonraker2mqtt/mqtt"
+	"moonraker2mqtt/websocket"
+)
+
+func newTestController(mqttClient *fakeMQTTClient, printers ...*Printer) *Controller {
+	cfg := &config.Config{
+		MQTT:    config.MQTTConfig{TopicPrefix: "moonraker"},
+		Logging: config.LoggingConfig{Level: "info", Format: "text"},
+	}
+	return NewController(printers, cfg, mqttClient, logger.NewWithWriter(&cfg.Logging, io.Discard))
+}
+
+func TestController_Apply(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		wantErr      bool
+		wantLevel    string
+		wantInterval int
+		wantObjects  []string
+		wantPaused   bool
+	}{
+		{
+			name:         "log level",
+			payload:      `{"log_level": "debug"}`,
+			wantLevel:    "debug",
+			wantInterval: 2,
+			wantObjects:  []string{"extruder", "print_stats"},
+		},
+		{
+			name:         "call interval",
+			payload:      `{"call_interval": 10}`,
+			wantLevel:    "info",
+			wantInterval: 10,
+			wantObjects:  []string{"extruder", "print_stats"},
+		},
+		{
+			name:         "add and remove objects",
+			payload:      `{"printer": "voron", "add_objects": {"heater_bed": ["temperature"]}, "remove_objects": ["extruder"]}`,
+			wantLevel:    "info",
+			wantInterval: 2,
+			wantObjects:  []string{"heater_bed", "print_stats"},
+		},
+		{
+			name:         "pause",
+			payload:      `{"paused": true}`,
+			wantLevel:    "info",
+			wantInterval: 2,
+			wantObjects:  []string{"extruder", "print_stats"},
+			wantPaused:   true,
+		},
+		{
+			name:         "invalid request is rejected as a whole",
+			payload:      `{"log_level": "debug", "call_interval": 0}`,
+			wantErr:      true,
+			wantLevel:    "info",
+			wantInterval: 2,
+			wantObjects:  []string{"extruder", "print_stats"},
+		},
+		{
+			name:         "unknown printer",
+			payload:      `{"printer": "ender", "paused": true}`,
+			wantErr:      true,
+			wantLevel:    "info",
+			wantInterval: 2,
+			wantObjects:  []string{"extruder", "print_stats"},
+		},
+		{
+			name:         "unknown log component",
+			payload:      `{"log_level": "debug", "log_components": {"websockt": "debug"}}`,
+			wantErr:      true,
+			wantLevel:    "info",
+			wantInterval: 2,
+			wantObjects:  []string{"extruder", "print_stats"},
+		},
+		{
+			name:         "invalid object fields",
+			payload:      `{"add_objects": {"heater_bed": "temperature"}}`,
+			wantErr:      true,
+			wantLevel:    "info",
+			wantInterval: 2,
+			wantObjects:  []string{"extruder", "print_stats"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mqttClient := &fakeMQTTClient{}
+			printer := newTestPrinter(mqttClient)
+			printer.callInterval = 2 * time.Second
+			printer.monitored = map[string]any{"print_stats": nil, "extruder": []any{"temperature"}}
+			controller := newTestController(mqttClient, printer)
+
+			var request ControlRequest
+			if err := json.Unmarshal([]byte(tt.payload), &request); err != nil {
+				t.Fatalf("invalid test payload: %v", err)
+			}
+
+			err := controller.Apply(request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			bridgeConfig := controller.Config()
+			if bridgeConfig.LogLevel != tt.wantLevel {
+				t.Errorf("log level = %s, want %s", bridgeConfig.LogLevel, tt.wantLevel)
+			}
+
+			settings := bridgeConfig.Printers[0]
+			if settings.CallInterval != tt.wantInterval {
+				t.Errorf("call interval = %d, want %d", settings.CallInterval, tt.wantInterval)
+			}
+			if settings.Paused != tt.wantPaused {
+				t.Errorf("paused = %t, want %t", settings.Paused, tt.wantPaused)
+			}
+			if len(settings.MonitoredObjects) != len(tt.wantObjects) {
+				t.Errorf("monitored objects = %v, want %v", settings.MonitoredObjects, tt.wantObjects)
+			}
+			for _, objectName := range tt.wantObjects {
+				if _, exists := settings.MonitoredObjects[objectName]; !exists {
+					t.Errorf("monitored objects = %v, missing %s", settings.MonitoredObjects, objectName)
+				}
+			}
+		})
+	}
+}
+
+func TestController_HandleControlPublishesConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		wantLevel string
+	}{
+		{"valid request", `{"log_level": "warn"}`, "warn"},
+		{"unknown field", `{"log_levle": "warn"}`, "info"},
+		{"malformed payload", `not json`, "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mqttClient := &fakeMQTTClient{}
+			controller := newTestController(mqttClient, newTestPrinter(mqttClient))
+
+			controller.handleControl(BridgeControlTopic("moonraker"), []byte(tt.payload))
+
+			payload, ok := mqttClient.last("moonraker/bridge/config")
+			if !ok {
+				t.Fatal("bridge config was not published")
+			}
+
+			var bridgeConfig BridgeConfig
+			if err := json.Unmarshal([]byte(payload), &bridgeConfig); err != nil {
+				t.Fatalf("invalid bridge config payload: %v", err)
+			}
+			if bridgeConfig.LogLevel != tt.wantLevel {
+				t.Errorf("log level = %s, want %s", bridgeConfig.LogLevel, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestPrinter_PausedSkipsObjects(t *testing.T) {
+	mqttClient := &fakeMQTTClient{}
+	printer := newTestPrinter(mqttClient)
+	printer.objectCache = moonraker.NewObjectCache()
+	printer.client = moonraker.NewClient(&config.MoonrakerConfig{}, testLogger{}, nil)
+	printer.SetPaused(true)
+
+	printer.OnNotification("notify_klippy_shutdown", nil)
+	printer.OnStateChanged(websocket.WEB_SOCKET_STATE_STOPPED)
+	if err := printer.publishInitialInfo(context.Background()); err != nil {
+		t.Fatalf("publishInitialInfo() error = %v", err)
+	}
+
+	updated := printer.objectCache.Merge(map[string]any{
+		"print_stats": map[string]any{"state": PRINT_STATE_PRINTING, "filename": "benchy.gcode"},
+	})
+	if err := printer.processUpdates(updated); err != nil {
+		t.Fatalf("processUpdates() error = %v", err)
+	}
+	if len(mqttClient.published) != 0 {
+		t.Errorf("published %d message(s) while paused", len(mqttClient.published))
+	}
+
+	current := printer.job.Current()
+	if current == nil || current.State != PRINT_STATE_PRINTING {
+		t.Fatalf("job tracker did not follow print_stats while paused: %+v", current)
+	}
+
+	printer.SetPaused(false)
+
+	payload, ok := mqttClient.last("moonraker/job/current")
+	if !ok || !strings.Contains(payload, "benchy.gcode") {
+		t.Errorf("job/current = %q (published %t) after resume, want the job started while paused", payload, ok)
+	}
+	if payload, _ := mqttClient.last("moonraker/availability"); payload != mqtt.AVAILABILITY_OFFLINE {
+		t.Errorf("availability = %q after resume, want %q", payload, mqtt.AVAILABILITY_OFFLINE)
+	}
+}
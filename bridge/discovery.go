@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"moonraker2mqtt/homeassistant"
+)
+
+func (p *Printer) PublishDiscovery(ctx context.Context) error {
+	discovery := p.homeAssistantDiscovery()
+	if discovery == nil || p.paused.Load() {
+		return nil
+	}
+
+	printerInfo, err := p.client.GetHostInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get printer info: %w", err)
+	}
+
+	availableObjects, err := p.client.GetSupportedObjects(ctx)
+	if err != nil {
+		p.logger.Warn("Failed to list printer objects, announcing all monitored objects: %v", err)
+	}
+
+	messages, err := discovery.Messages(&homeassistant.PrinterDescription{
+		Hostname:         printerInfo.Hostname,
+		SoftwareVersion:  printerInfo.SoftwareVersion,
+		AvailableObjects: availableObjects,
+		MonitoredObjects: p.monitoredObjects(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build discovery messages: %w", err)
+	}
+
+	for _, message := range messages {
+		if err := p.mqttClient.Publish(message.Topic, message.Payload, p.currentMQTTConfig().QoS, true, 3); err != nil {
+			return fmt.Errorf("failed to publish discovery config %s: %w", message.Topic, err)
+		}
+	}
+
+	p.logger.Info("Published %d Home Assistant discovery configs", len(messages))
+	return nil
+}
+
+func (p *Printer) homeAssistantDiscovery() *homeassistant.Discovery {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.discovery
+}
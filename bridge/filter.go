@@ -0,0 +1,143 @@
+package bridge
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func newChangeFilter(publishConfig *config.PublishConfig) *changeFilter {
+	return &changeFilter{
+		config:    publishConfig,
+		published: make(map[string]publishedValue),
+	}
+}
+
+func (f *changeFilter) Enabled() bool {
+	return f.settings().OnlyOnChange
+}
+
+func (f *changeFilter) SetConfig(publishConfig *config.PublishConfig) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.config = publishConfig
+	f.published = make(map[string]publishedValue)
+}
+
+func (f *changeFilter) settings() *config.PublishConfig {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.config
+}
+
+func (f *changeFilter) ShouldPublish(topic, objectName string, value any, now time.Time) bool {
+	return f.shouldPublish(topic, now, func(previous any) bool {
+		return f.changed(objectName, previous, value)
+	})
+}
+
+func (f *changeFilter) ShouldPublishField(topic, objectName, field string, value any, now time.Time) bool {
+	return f.shouldPublish(topic, now, func(previous any) bool {
+		return f.fieldChanged(objectName, field, previous, value)
+	})
+}
+
+func (f *changeFilter) shouldPublish(topic string, now time.Time, changed func(previous any) bool) bool {
+	f.mux.Lock()
+	publishConfig := f.config
+	last, exists := f.published[topic]
+	f.mux.Unlock()
+
+	if !publishConfig.OnlyOnChange {
+		return true
+	}
+
+	if !exists {
+		return true
+	}
+
+	elapsed := now.Sub(last.at)
+
+	if heartbeat := publishConfig.GetHeartbeatInterval(); heartbeat > 0 && elapsed >= heartbeat {
+		return true
+	}
+
+	if elapsed < publishConfig.GetMinInterval() {
+		return false
+	}
+
+	return changed(last.value)
+}
+
+func (f *changeFilter) Record(topic string, value any, now time.Time) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if !f.config.OnlyOnChange {
+		return
+	}
+
+	f.published[topic] = publishedValue{value: value, at: now}
+}
+
+func (f *changeFilter) Reset() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.published = make(map[string]publishedValue)
+}
+
+func (f *changeFilter) changed(objectName string, previous, current any) bool {
+	previousFields, previousIsObject := previous.(map[string]any)
+	currentFields, currentIsObject := current.(map[string]any)
+
+	if !previousIsObject || !currentIsObject {
+		return f.fieldChanged(objectName, "", previous, current)
+	}
+
+	if len(previousFields) != len(currentFields) {
+		return true
+	}
+
+	for field, value := range currentFields {
+		previousValue, exists := previousFields[field]
+		if !exists || f.fieldChanged(objectName, field, previousValue, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *changeFilter) fieldChanged(objectName, field string, previous, current any) bool {
+	previousNumber, previousIsNumber := toFloat(previous)
+	currentNumber, currentIsNumber := toFloat(current)
+
+	if previousIsNumber && currentIsNumber {
+		deadband := f.settings().GetDeadband(objectName, field)
+		if deadband == 0 {
+			return previousNumber != currentNumber
+		}
+		return math.Abs(currentNumber-previousNumber) >= deadband
+	}
+
+	return !reflect.DeepEqual(previous, current)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,130 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func TestChangeFilter_ShouldPublish(t *testing.T) {
+	start := time.Now()
+	topic := "moonraker/objects/extruder"
+	previous := map[string]any{"temperature": 210.0, "target": 210.0}
+
+	tests := []struct {
+		name    string
+		config  config.PublishConfig
+		value   any
+		elapsed time.Duration
+		want    bool
+	}{
+		{
+			name:    "disabled always publishes",
+			config:  config.PublishConfig{OnlyOnChange: false},
+			value:   previous,
+			elapsed: time.Second,
+			want:    true,
+		},
+		{
+			name:    "unchanged value is skipped",
+			config:  config.PublishConfig{OnlyOnChange: true},
+			value:   map[string]any{"temperature": 210.0, "target": 210.0},
+			elapsed: time.Second,
+			want:    false,
+		},
+		{
+			name:    "changed value is published",
+			config:  config.PublishConfig{OnlyOnChange: true},
+			value:   map[string]any{"temperature": 210.1, "target": 210.0},
+			elapsed: time.Second,
+			want:    true,
+		},
+		{
+			name:    "change within deadband is skipped",
+			config:  config.PublishConfig{OnlyOnChange: true, Deadbands: map[string]float64{"temperature": 0.5}},
+			value:   map[string]any{"temperature": 210.4, "target": 210.0},
+			elapsed: time.Second,
+			want:    false,
+		},
+		{
+			name:    "change beyond deadband is published",
+			config:  config.PublishConfig{OnlyOnChange: true, Deadbands: map[string]float64{"temperature": 0.5}},
+			value:   map[string]any{"temperature": 210.6, "target": 210.0},
+			elapsed: time.Second,
+			want:    true,
+		},
+		{
+			name:    "object specific deadband wins",
+			config:  config.PublishConfig{OnlyOnChange: true, Deadbands: map[string]float64{"temperature": 0.5, "extruder.temperature": 2}},
+			value:   map[string]any{"temperature": 211.0, "target": 210.0},
+			elapsed: time.Second,
+			want:    false,
+		},
+		{
+			name:    "non numeric change is published",
+			config:  config.PublishConfig{OnlyOnChange: true, Deadbands: map[string]float64{"temperature": 0.5}},
+			value:   map[string]any{"temperature": 210.0, "target": "off"},
+			elapsed: time.Second,
+			want:    true,
+		},
+		{
+			name:    "new field is published",
+			config:  config.PublishConfig{OnlyOnChange: true},
+			value:   map[string]any{"temperature": 210.0, "target": 210.0, "power": 0.5},
+			elapsed: time.Second,
+			want:    true,
+		},
+		{
+			name:    "change within min interval is skipped",
+			config:  config.PublishConfig{OnlyOnChange: true, MinInterval: 5},
+			value:   map[string]any{"temperature": 215.0, "target": 210.0},
+			elapsed: 2 * time.Second,
+			want:    false,
+		},
+		{
+			name:    "change after min interval is published",
+			config:  config.PublishConfig{OnlyOnChange: true, MinInterval: 5},
+			value:   map[string]any{"temperature": 215.0, "target": 210.0},
+			elapsed: 5 * time.Second,
+			want:    true,
+		},
+		{
+			name:    "unchanged value is republished after heartbeat",
+			config:  config.PublishConfig{OnlyOnChange: true, HeartbeatInterval: 60},
+			value:   map[string]any{"temperature": 210.0, "target": 210.0},
+			elapsed: time.Minute,
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newChangeFilter(&tt.config)
+			filter.Record(topic, previous, start)
+
+			if got := filter.ShouldPublish(topic, "extruder", tt.value, start.Add(tt.elapsed)); got != tt.want {
+				t.Errorf("ShouldPublish() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeFilter_FirstPublishAndReset(t *testing.T) {
+	filter := newChangeFilter(&config.PublishConfig{OnlyOnChange: true})
+	now := time.Now()
+
+	if !filter.ShouldPublish("moonraker/klipper/state", "", "ready", now) {
+		t.Fatal("ShouldPublish() = false for a topic never published")
+	}
+
+	filter.Record("moonraker/klipper/state", "ready", now)
+	if filter.ShouldPublish("moonraker/klipper/state", "", "ready", now) {
+		t.Fatal("ShouldPublish() = true for an unchanged value")
+	}
+
+	filter.Reset()
+	if !filter.ShouldPublish("moonraker/klipper/state", "", "ready", now) {
+		t.Error("ShouldPublish() = false after Reset()")
+	}
+}
@@ -0,0 +1,88 @@
+package bridge
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"moonraker2mqtt/config"
+)
+
+var axisNames = []string{"x", "y", "z", "e"}
+
+func flattenObject(objectData map[string]any, mode string) []flatField {
+	if mode != config.FLATTEN_MODE_INDEX && mode != config.FLATTEN_MODE_AXES {
+		return nil
+	}
+
+	fieldNames := make([]string, 0, len(objectData))
+	for fieldName := range objectData {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+
+	var fields []flatField
+	for _, fieldName := range fieldNames {
+		fields = flattenValue(fields, fieldName, []string{fieldName}, objectData[fieldName], mode)
+	}
+	return fields
+}
+
+func flattenValue(fields []flatField, fieldName string, path []string, value any, mode string) []flatField {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			fields = flattenValue(fields, fieldName, appendPath(path, key), v[key], mode)
+		}
+	case []any:
+		for i, item := range v {
+			fields = flattenValue(fields, fieldName, appendPath(path, elementName(i, len(v), mode)), item, mode)
+		}
+	default:
+		fields = append(fields, flatField{
+			path:  strings.Join(path, "/"),
+			field: fieldName,
+			value: value,
+		})
+	}
+	return fields
+}
+
+func appendPath(path []string, element string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, element)
+}
+
+func elementName(index, length int, mode string) string {
+	if mode == config.FLATTEN_MODE_AXES && length <= len(axisNames) {
+		return axisNames[index]
+	}
+	return strconv.Itoa(index)
+}
+
+func formatScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
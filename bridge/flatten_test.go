@@ -0,0 +1,98 @@
+package bridge
+
+import (
+	"reflect"
+	"testing"
+
+	"moonraker2mqtt/config"
+)
+
+func TestFlattenObject(t *testing.T) {
+	objectData := map[string]any{
+		"position":     []any{10.0, 20.5, 0.2, 1234.0},
+		"homed_axes":   "xyz",
+		"max_velocity": 300.0,
+		"extruder":     map[string]any{"pressure_advance": 0.04},
+		"matrix":       []any{1.0, 2.0, 3.0, 4.0, 5.0},
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want map[string]string
+	}{
+		{
+			name: "none",
+			mode: config.FLATTEN_MODE_NONE,
+			want: map[string]string{},
+		},
+		{
+			name: "index",
+			mode: config.FLATTEN_MODE_INDEX,
+			want: map[string]string{
+				"extruder/pressure_advance": "0.04",
+				"homed_axes":                "xyz",
+				"matrix/0":                  "1",
+				"matrix/1":                  "2",
+				"matrix/2":                  "3",
+				"matrix/3":                  "4",
+				"matrix/4":                  "5",
+				"max_velocity":              "300",
+				"position/0":                "10",
+				"position/1":                "20.5",
+				"position/2":                "0.2",
+				"position/3":                "1234",
+			},
+		},
+		{
+			name: "axes",
+			mode: config.FLATTEN_MODE_AXES,
+			want: map[string]string{
+				"extruder/pressure_advance": "0.04",
+				"homed_axes":                "xyz",
+				"matrix/0":                  "1",
+				"matrix/1":                  "2",
+				"matrix/2":                  "3",
+				"matrix/3":                  "4",
+				"matrix/4":                  "5",
+				"max_velocity":              "300",
+				"position/x":                "10",
+				"position/y":                "20.5",
+				"position/z":                "0.2",
+				"position/e":                "1234",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, field := range flattenObject(objectData, tt.mode) {
+				got[field.path] = formatScalar(field.value)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flattenObject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatScalar(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{nil, "null"},
+		{"printing", "printing"},
+		{true, "true"},
+		{0.1, "0.1"},
+		{210.0, "210"},
+	}
+
+	for _, tt := range tests {
+		if got := formatScalar(tt.value); got != tt.want {
+			t.Errorf("formatScalar(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"moonraker2mqtt/mqtt"
+	"moonraker2mqtt/websocket"
+)
+
+func BridgeHealthTopic(topicPrefix string) string {
+	return fmt.Sprintf("%s/bridge/health", topicPrefix)
+}
+
+func (p *Printer) Health(now time.Time) PrinterHealth {
+	return newPrinterHealth(p.Name(), p.client.GetState(), p.KlippyState(), p.client.LastSeen(), now)
+}
+
+func (p *Printer) KlippyState() string {
+	p.klippyMux.Lock()
+	defer p.klippyMux.Unlock()
+	return p.klippyState
+}
+
+func (h PrinterHealth) Ready() bool {
+	return h.Connected && h.KlippyState == KLIPPY_STATE_READY
+}
+
+func NewBridgeHealth(mqttConnected bool, printers []*Printer, now time.Time) BridgeHealth {
+	health := BridgeHealth{
+		MQTTConnected: mqttConnected,
+		Printers:      make([]PrinterHealth, 0, len(printers)),
+		Timestamp:     now.UTC().Format(time.RFC3339),
+	}
+
+	for _, printer := range printers {
+		health.Printers = append(health.Printers, printer.Health(now))
+	}
+
+	return health
+}
+
+func PublishBridgeHealth(client mqtt.MQTTClient, topicPrefix string, qos byte, health BridgeHealth) error {
+	payload, err := json.Marshal(health)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bridge health: %w", err)
+	}
+
+	return client.Publish(BridgeHealthTopic(topicPrefix), payload, qos, true, 1)
+}
+
+func newPrinterHealth(name, state, klippyState string, lastSeen, now time.Time) PrinterHealth {
+	health := PrinterHealth{
+		Name:        name,
+		State:       state,
+		Connected:   state == websocket.WEB_SOCKET_STATE_CONNECTED,
+		KlippyState: klippyState,
+	}
+
+	if !lastSeen.IsZero() {
+		idle := math.Round(now.Sub(lastSeen).Seconds()*10) / 10
+		health.LastSeen = lastSeen.UTC().Format(time.RFC3339)
+		health.IdleSeconds = &idle
+	}
+
+	return health
+}
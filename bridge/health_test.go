@@ -0,0 +1,60 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	"moonraker2mqtt/websocket"
+)
+
+func TestNewPrinterHealth(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+
+	health := newPrinterHealth("voron", websocket.WEB_SOCKET_STATE_CONNECTED, KLIPPY_STATE_READY, now.Add(-2500*time.Millisecond), now)
+	if !health.Connected {
+		t.Error("newPrinterHealth() Connected = false, want true")
+	}
+	if !health.Ready() {
+		t.Error("newPrinterHealth() Ready() = false, want true when connected and Klippy is ready")
+	}
+	if health.LastSeen != "2024-05-01T12:00:27Z" {
+		t.Errorf("newPrinterHealth() LastSeen = %s, want 2024-05-01T12:00:27Z", health.LastSeen)
+	}
+	if health.IdleSeconds == nil || *health.IdleSeconds != 2.5 {
+		t.Errorf("newPrinterHealth() IdleSeconds = %v, want 2.5", health.IdleSeconds)
+	}
+
+	health = newPrinterHealth("ender", websocket.WEB_SOCKET_STATE_STOPPED, KLIPPY_STATE_READY, time.Time{}, now)
+	if health.Connected {
+		t.Error("newPrinterHealth() Connected = true for a stopped connection")
+	}
+	if health.Ready() {
+		t.Error("newPrinterHealth() Ready() = true for a stopped connection")
+	}
+	if health.LastSeen != "" || health.IdleSeconds != nil {
+		t.Errorf("newPrinterHealth() = %+v, want no last seen for a printer never heard from", health)
+	}
+}
+
+func TestPrinterHealth_Ready(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		klippyState string
+		want        bool
+	}{
+		{"connected and ready", websocket.WEB_SOCKET_STATE_CONNECTED, KLIPPY_STATE_READY, true},
+		{"klippy shutdown", websocket.WEB_SOCKET_STATE_CONNECTED, KLIPPY_STATE_SHUTDOWN, false},
+		{"klippy unknown", websocket.WEB_SOCKET_STATE_CONNECTED, "", false},
+		{"connecting", websocket.WEB_SOCKET_STATE_CONNECTING, KLIPPY_STATE_READY, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := newPrinterHealth("voron", tt.state, tt.klippyState, time.Time{}, time.Now())
+			if got := health.Ready(); got != tt.want {
+				t.Errorf("Ready() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package bridge
+
+import (
+	"time"
+)
+
+func newJobTracker() *jobTracker {
+	return &jobTracker{}
+}
+
+func (t *jobTracker) Current() *JobSummary {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.current == nil {
+		return nil
+	}
+	current := *t.current
+	return &current
+}
+
+func (t *jobTracker) UpdatePrintStats(printStats map[string]any, now time.Time) ([]JobEvent, bool) {
+	state, ok := printStats["state"].(string)
+	if !ok {
+		return nil, false
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	previous := t.state
+	t.state = state
+
+	if previous == state {
+		return nil, false
+	}
+
+	if previous == "" {
+		if isActivePrintState(state) {
+			t.current = &JobSummary{StartTime: unixSeconds(now)}
+			t.applyPrintStats(printStats)
+			return nil, true
+		}
+		return nil, false
+	}
+
+	var event string
+	switch {
+	case state == PRINT_STATE_PRINTING && previous == PRINT_STATE_PAUSED:
+		event = JOB_EVENT_RESUMED
+	case state == PRINT_STATE_PRINTING:
+		event = JOB_EVENT_STARTED
+		if t.current == nil || t.current.Outcome != "" {
+			t.current = &JobSummary{StartTime: unixSeconds(now)}
+		}
+	case state == PRINT_STATE_PAUSED:
+		event = JOB_EVENT_PAUSED
+	case !isActivePrintState(previous) || t.current == nil:
+		return nil, false
+	case state == PRINT_STATE_COMPLETE:
+		event = JOB_EVENT_COMPLETED
+	case state == PRINT_STATE_ERROR:
+		event = JOB_EVENT_ERROR
+	default:
+		event = JOB_EVENT_CANCELLED
+	}
+
+	if t.current == nil {
+		t.current = &JobSummary{}
+	}
+	t.applyPrintStats(printStats)
+
+	if outcome := jobOutcome(event); outcome != "" {
+		t.current.Outcome = outcome
+		t.current.EndTime = unixSeconds(now)
+	}
+
+	return []JobEvent{t.newEvent(event)}, true
+}
+
+func (t *jobTracker) UpdateHistory(params any, now time.Time) ([]JobEvent, bool) {
+	items, ok := params.([]any)
+	if !ok || len(items) == 0 {
+		return nil, false
+	}
+
+	change, ok := items[0].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	job, ok := change["job"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	jobID, _ := job["job_id"].(string)
+	filename, _ := job["filename"].(string)
+
+	switch change["action"] {
+	case "added":
+		if t.current == nil || (t.current.JobID != "" && t.current.JobID != jobID) || t.current.Outcome != "" {
+			t.current = &JobSummary{StartTime: unixSeconds(now)}
+		}
+		t.current.JobID = jobID
+		t.current.Filename = filename
+		if startTime, ok := job["start_time"].(float64); ok {
+			t.current.StartTime = startTime
+		}
+		return nil, true
+	case "finished":
+		if t.current == nil || (t.current.JobID != "" && t.current.JobID != jobID) {
+			t.current = &JobSummary{JobID: jobID, Filename: filename}
+		}
+		t.current.JobID = jobID
+		if filename != "" {
+			t.current.Filename = filename
+		}
+		if endTime, ok := job["end_time"].(float64); ok {
+			t.current.EndTime = endTime
+		}
+		if totalDuration, ok := job["total_duration"].(float64); ok {
+			t.current.TotalDuration = totalDuration
+		}
+		if printDuration, ok := job["print_duration"].(float64); ok {
+			t.current.PrintDuration = printDuration
+		}
+		if filamentUsed, ok := job["filament_used"].(float64); ok {
+			t.current.FilamentUsed = filamentUsed
+		}
+
+		if t.current.Outcome != "" {
+			return nil, true
+		}
+
+		status, _ := job["status"].(string)
+		event := historyEvent(status)
+		t.current.Outcome = jobOutcome(event)
+		if t.current.EndTime == 0 {
+			t.current.EndTime = unixSeconds(now)
+		}
+		t.state = terminalPrintState(event)
+
+		return []JobEvent{t.newEvent(event)}, true
+	}
+
+	return nil, false
+}
+
+func (t *jobTracker) applyPrintStats(printStats map[string]any) {
+	t.current.State, _ = printStats["state"].(string)
+	if filename, ok := printStats["filename"].(string); ok && filename != "" {
+		t.current.Filename = filename
+	}
+	if message, ok := printStats["message"].(string); ok {
+		t.current.Message = message
+	}
+	if totalDuration, ok := printStats["total_duration"].(float64); ok {
+		t.current.TotalDuration = totalDuration
+	}
+	if printDuration, ok := printStats["print_duration"].(float64); ok {
+		t.current.PrintDuration = printDuration
+	}
+	if filamentUsed, ok := printStats["filament_used"].(float64); ok {
+		t.current.FilamentUsed = filamentUsed
+	}
+}
+
+func (t *jobTracker) newEvent(event string) JobEvent {
+	return JobEvent{
+		Event:      event,
+		JobSummary: *t.current,
+	}
+}
+
+func isActivePrintState(state string) bool {
+	return state == PRINT_STATE_PRINTING || state == PRINT_STATE_PAUSED
+}
+
+func jobOutcome(event string) string {
+	switch event {
+	case JOB_EVENT_COMPLETED, JOB_EVENT_CANCELLED, JOB_EVENT_ERROR:
+		return event
+	default:
+		return ""
+	}
+}
+
+func historyEvent(status string) string {
+	switch status {
+	case "completed":
+		return JOB_EVENT_COMPLETED
+	case "cancelled":
+		return JOB_EVENT_CANCELLED
+	default:
+		return JOB_EVENT_ERROR
+	}
+}
+
+func terminalPrintState(event string) string {
+	switch event {
+	case JOB_EVENT_COMPLETED:
+		return PRINT_STATE_COMPLETE
+	case JOB_EVENT_CANCELLED:
+		return PRINT_STATE_CANCELLED
+	default:
+		return PRINT_STATE_ERROR
+	}
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
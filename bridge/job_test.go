@@ -0,0 +1,111 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJobTracker_Lifecycle(t *testing.T) {
+	tracker := newJobTracker()
+	now := time.Now()
+
+	steps := []struct {
+		name      string
+		state     string
+		wantEvent string
+	}{
+		{"initial standby", PRINT_STATE_STANDBY, ""},
+		{"print starts", PRINT_STATE_PRINTING, JOB_EVENT_STARTED},
+		{"print pauses", PRINT_STATE_PAUSED, JOB_EVENT_PAUSED},
+		{"print resumes", PRINT_STATE_PRINTING, JOB_EVENT_RESUMED},
+		{"print completes", PRINT_STATE_COMPLETE, JOB_EVENT_COMPLETED},
+		{"back to standby", PRINT_STATE_STANDBY, ""},
+		{"next print starts", PRINT_STATE_PRINTING, JOB_EVENT_STARTED},
+		{"next print is cancelled", PRINT_STATE_CANCELLED, JOB_EVENT_CANCELLED},
+		{"third print starts", PRINT_STATE_PRINTING, JOB_EVENT_STARTED},
+		{"third print fails", PRINT_STATE_ERROR, JOB_EVENT_ERROR},
+	}
+
+	for _, step := range steps {
+		events, _ := tracker.UpdatePrintStats(map[string]any{
+			"state":          step.state,
+			"filename":       "benchy.gcode",
+			"total_duration": 120.0,
+			"print_duration": 100.0,
+			"filament_used":  1500.0,
+		}, now)
+
+		if step.wantEvent == "" {
+			if len(events) != 0 {
+				t.Errorf("%s: got events %v, want none", step.name, events)
+			}
+			continue
+		}
+
+		if len(events) != 1 || events[0].Event != step.wantEvent {
+			t.Errorf("%s: got events %v, want %s", step.name, events, step.wantEvent)
+			continue
+		}
+
+		if events[0].Filename != "benchy.gcode" || events[0].PrintDuration != 100 || events[0].FilamentUsed != 1500 {
+			t.Errorf("%s: unexpected job summary %+v", step.name, events[0].JobSummary)
+		}
+	}
+
+	if current := tracker.Current(); current == nil || current.Outcome != JOB_EVENT_ERROR {
+		t.Errorf("Current() = %+v, want outcome %s", current, JOB_EVENT_ERROR)
+	}
+}
+
+func TestJobTracker_StartupMidPrint(t *testing.T) {
+	tracker := newJobTracker()
+
+	events, changed := tracker.UpdatePrintStats(map[string]any{"state": PRINT_STATE_PRINTING, "filename": "benchy.gcode"}, time.Now())
+	if len(events) != 0 || !changed {
+		t.Errorf("UpdatePrintStats() = %v, %v, want no events and a changed job", events, changed)
+	}
+
+	if current := tracker.Current(); current == nil || current.Filename != "benchy.gcode" {
+		t.Errorf("Current() = %+v, want benchy.gcode", current)
+	}
+}
+
+func TestJobTracker_History(t *testing.T) {
+	tracker := newJobTracker()
+	now := time.Now()
+
+	tracker.UpdatePrintStats(map[string]any{"state": PRINT_STATE_STANDBY}, now)
+	tracker.UpdatePrintStats(map[string]any{"state": PRINT_STATE_PRINTING, "filename": "benchy.gcode"}, now)
+
+	_, changed := tracker.UpdateHistory([]any{map[string]any{
+		"action": "added",
+		"job":    map[string]any{"job_id": "000001", "filename": "benchy.gcode", "start_time": 1700000000.0},
+	}}, now)
+	if !changed {
+		t.Fatal("UpdateHistory(added) did not change the current job")
+	}
+
+	events, _ := tracker.UpdateHistory([]any{map[string]any{
+		"action": "finished",
+		"job": map[string]any{
+			"job_id":         "000001",
+			"filename":       "benchy.gcode",
+			"status":         "klippy_shutdown",
+			"end_time":       1700003600.0,
+			"total_duration": 3600.0,
+			"print_duration": 3500.0,
+			"filament_used":  4200.0,
+		},
+	}}, now)
+	if len(events) != 1 || events[0].Event != JOB_EVENT_ERROR {
+		t.Fatalf("UpdateHistory(finished) = %v, want %s", events, JOB_EVENT_ERROR)
+	}
+	if events[0].JobID != "000001" || events[0].StartTime != 1700000000 || events[0].PrintDuration != 3500 {
+		t.Errorf("unexpected job summary %+v", events[0].JobSummary)
+	}
+
+	events, _ = tracker.UpdatePrintStats(map[string]any{"state": PRINT_STATE_ERROR}, now)
+	if len(events) != 0 {
+		t.Errorf("UpdatePrintStats() after history = %v, want no duplicate event", events)
+	}
+}
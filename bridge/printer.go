@@ -0,0 +1,890 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/homeassistant"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/moonraker"
+	"moonraker2mqtt/mqtt"
+	"moonraker2mqtt/websocket"
+)
+
+func NewPrinter(printerConfig *config.PrinterConfig, cfg *config.Config, mqttClient mqtt.MQTTClient, logger logger.Logger) *Printer {
+	p := &Printer{
+		config:          printerConfig,
+		mqttConfig:      &cfg.MQTT,
+		publishConfig:   &cfg.Publish,
+		topicPrefix:     printerConfig.GetTopicPrefix(cfg.MQTT.TopicPrefix),
+		mqttClient:      mqttClient,
+		objectCache:     moonraker.NewObjectCache(),
+		filter:          newChangeFilter(&cfg.Publish),
+		job:             newJobTracker(),
+		commands:        make(chan commandRequest, COMMAND_QUEUE_SIZE),
+		callInterval:    time.Duration(printerConfig.CallInterval) * time.Second,
+		telemetry:       telemetryObjects(&cfg.HTTP),
+		intervalChanged: make(chan struct{}, 1),
+		logger:          logger.WithComponent(LOG_COMPONENT).With("printer", printerConfig.Name),
+	}
+
+	p.client = moonraker.NewClient(&printerConfig.MoonrakerConfig, logger.With("printer", printerConfig.Name), p)
+	p.client.SetRPCFilter(cfg.MQTT.IsRPCMethodAllowed)
+
+	p.discovery = p.newDiscovery(cfg)
+
+	return p
+}
+
+func (p *Printer) newDiscovery(cfg *config.Config) *homeassistant.Discovery {
+	if !cfg.HomeAssistant.Enabled {
+		return nil
+	}
+
+	var availabilityTopics []string
+	if cfg.MQTT.AvailabilityEnabled {
+		availabilityTopics = []string{BridgeAvailabilityTopic(cfg.MQTT.TopicPrefix), p.availabilityTopic()}
+	}
+
+	nodeID := cfg.HomeAssistant.NodeID
+	if len(cfg.Printers) > 0 {
+		nodeID = p.Name()
+		if cfg.HomeAssistant.NodeID != "" {
+			nodeID = fmt.Sprintf("%s_%s", cfg.HomeAssistant.NodeID, p.Name())
+		}
+	}
+
+	return homeassistant.NewDiscovery(
+		cfg.HomeAssistant.GetDiscoveryPrefix(),
+		nodeID,
+		p.TopicPrefix(),
+		cfg.MQTT.CommandsEnabled,
+		availabilityTopics,
+	)
+}
+
+func BridgeAvailabilityTopic(topicPrefix string) string {
+	return fmt.Sprintf("%s/bridge/availability", topicPrefix)
+}
+
+func (p *Printer) Name() string {
+	return p.currentConfig().Name
+}
+
+func (p *Printer) currentConfig() *config.PrinterConfig {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.config
+}
+
+func (p *Printer) currentMQTTConfig() *config.MQTTConfig {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.mqttConfig
+}
+
+func (p *Printer) currentPublishConfig() *config.PublishConfig {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.publishConfig
+}
+
+func (p *Printer) TopicPrefix() string {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.topicPrefix
+}
+
+func (p *Printer) Timeout() time.Duration {
+	return p.currentConfig().GetTimeout()
+}
+
+func (p *Printer) Start(ctx context.Context) error {
+	p.ctx = ctx
+
+	go p.processCommands(ctx)
+	p.SubscribeCommands()
+
+	if err := p.client.Connect(ctx); err != nil {
+		if !p.currentConfig().AutoReconnect {
+			return fmt.Errorf("failed to connect to Moonraker: %w", err)
+		}
+
+		p.logger.Warn("Could not connect to Moonraker, will keep retrying: %v", err)
+		p.started.Store(true)
+		go p.periodicMonitoring(ctx)
+		return nil
+	}
+
+	p.logger.Info("Connected to Moonraker")
+
+	maxRetries := 3
+	for retries := 0; retries < maxRetries; retries++ {
+		if err := p.publishInitialInfo(ctx); err != nil {
+			p.logger.Warn("Failed to publish initial info (attempt %d/%d): %v", retries+1, maxRetries, err)
+			if retries == maxRetries-1 {
+				p.logger.Error("Failed to publish initial info after %d attempts, continuing anyway", maxRetries)
+			} else {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second * time.Duration(retries+1)):
+				}
+			}
+		} else {
+			p.logger.Info("Successfully published initial info")
+			break
+		}
+	}
+
+	if klippyState, err := p.client.GetKlippyState(ctx); err != nil {
+		p.logger.Warn("Failed to get klipper state: %v", err)
+	} else if err := p.publishKlippyState(klippyState); err != nil {
+		p.logger.Warn("Failed to publish klipper state: %v", err)
+	}
+
+	if err := p.subscribeObjects(ctx); err != nil {
+		p.logger.Warn("Failed to subscribe to monitored objects, will retry: %v", err)
+	} else {
+		p.logger.Info("Subscribed to monitored objects")
+	}
+
+	if p.homeAssistantDiscovery() != nil {
+		if err := p.PublishDiscovery(ctx); err != nil {
+			p.logger.Warn("Failed to publish Home Assistant discovery: %v", err)
+		}
+	}
+
+	p.started.Store(true)
+	go p.periodicMonitoring(ctx)
+
+	return nil
+}
+
+func (p *Printer) Stop() {
+	if err := p.client.Disconnect(); err != nil {
+		p.logger.Error("Failed to disconnect from Moonraker: %v", err)
+	}
+}
+
+func (p *Printer) Connect(ctx context.Context) error {
+	return p.client.Connect(ctx)
+}
+
+func (p *Printer) IsConnected() bool {
+	return p.client.IsConnected()
+}
+
+func (p *Printer) OnStateChanged(state string) {
+	p.logger.Debug("Moonraker state changed: %s", state)
+
+	if state != websocket.WEB_SOCKET_STATE_CONNECTED {
+		p.subscribed.Store(false)
+		p.objectCache.Reset()
+		p.publishAvailability(false)
+	} else if p.started.Load() {
+		p.logger.Info("Moonraker reconnected, resynchronizing")
+		go p.resync(p.ctx)
+	}
+
+	if p.paused.Load() {
+		return
+	}
+
+	if p.mqttClient.IsConnected() {
+		mqttConfig := p.currentMQTTConfig()
+		topic := fmt.Sprintf("%s/state", p.TopicPrefix())
+		payload := []byte(state)
+		if err := p.mqttClient.Publish(topic, payload, mqttConfig.QoS, mqttConfig.Retain, 3); err != nil {
+			p.logger.Error("Failed to publish state to MQTT after retries: %v", err)
+		}
+	} else {
+		p.logger.Warn("Cannot publish state change - MQTT not connected")
+	}
+}
+
+func (p *Printer) OnNotification(method string, params any) {
+	p.logger.Debug("Received notification: %s", method)
+
+	switch method {
+	case "notify_status_update":
+		p.handleStatusUpdate(params)
+		return
+	case "notify_gcode_response":
+		p.publishConsole(params)
+		return
+	case "notify_history_changed":
+		p.publishJobEvents(p.job.UpdateHistory(params, time.Now()))
+	case "notify_klippy_ready":
+		p.handleKlippyState(KLIPPY_STATE_READY)
+	case "notify_klippy_shutdown":
+		p.handleKlippyState(KLIPPY_STATE_SHUTDOWN)
+	case "notify_klippy_disconnected":
+		p.handleKlippyState(KLIPPY_STATE_DISCONNECTED)
+	}
+
+	if p.paused.Load() {
+		return
+	}
+
+	if p.mqttClient.IsConnected() {
+		topic := fmt.Sprintf("%s/notifications/%s", p.TopicPrefix(), method)
+
+		data, err := json.Marshal(params)
+		if err != nil {
+			p.logger.Error("Failed to marshal notification params: %v", err)
+			return
+		}
+
+		mqttConfig := p.currentMQTTConfig()
+		if err := p.mqttClient.Publish(topic, data, mqttConfig.QoS, mqttConfig.Retain, 3); err != nil {
+			p.logger.Error("Failed to publish notification to MQTT after retries: %v", err)
+		}
+	} else {
+		p.logger.Warn("Cannot publish notification '%s' - MQTT not connected", method)
+	}
+}
+
+func (p *Printer) handleKlippyState(state string) {
+	p.logger.Info("Klippy state changed: %s", state)
+
+	if state != KLIPPY_STATE_READY {
+		p.subscribed.Store(false)
+		p.objectCache.Reset()
+	}
+
+	if err := p.publishKlippyState(state); err != nil {
+		p.logger.Error("Failed to publish klipper state: %v", err)
+	}
+
+	if state == KLIPPY_STATE_READY && p.started.Load() {
+		go p.resync(p.ctx)
+	}
+}
+
+func (p *Printer) publishKlippyState(state string) error {
+	p.klippyMux.Lock()
+	p.klippyState = state
+	p.klippyMux.Unlock()
+
+	if p.paused.Load() {
+		return nil
+	}
+
+	p.publishAvailability(state == KLIPPY_STATE_READY)
+
+	if !p.mqttClient.IsConnected() {
+		return fmt.Errorf("MQTT not connected")
+	}
+
+	now := time.Now()
+	topic := fmt.Sprintf("%s/klipper/state", p.TopicPrefix())
+	if !p.filter.ShouldPublish(topic, "", state, now) {
+		return nil
+	}
+
+	if err := p.mqttClient.Publish(topic, []byte(state), p.currentMQTTConfig().QoS, false, 3); err != nil {
+		return fmt.Errorf("failed to publish klipper state: %w", err)
+	}
+	p.filter.Record(topic, state, now)
+
+	return nil
+}
+
+func (p *Printer) resync(ctx context.Context) {
+	p.resyncMux.Lock()
+	defer p.resyncMux.Unlock()
+
+	if ctx.Err() != nil || !p.client.IsConnected() {
+		return
+	}
+
+	if err := p.publishInitialInfo(ctx); err != nil {
+		p.logger.Warn("Failed to republish initial info: %v", err)
+	}
+
+	klippyState, err := p.client.GetKlippyState(ctx)
+	if err != nil {
+		p.logger.Warn("Failed to get klipper state: %v", err)
+		return
+	}
+
+	if err := p.publishKlippyState(klippyState); err != nil {
+		p.logger.Warn("Failed to publish klipper state: %v", err)
+	}
+
+	if klippyState != KLIPPY_STATE_READY {
+		p.logger.Info("Klippy is %s, subscriptions will be restored once it is ready", klippyState)
+		return
+	}
+
+	if err := p.subscribeObjects(ctx); err != nil {
+		p.logger.Warn("Failed to resubscribe to monitored objects: %v", err)
+		return
+	}
+	p.logger.Info("Resubscribed to monitored objects")
+
+	if p.homeAssistantDiscovery() != nil {
+		if err := p.PublishDiscovery(ctx); err != nil {
+			p.logger.Warn("Failed to publish Home Assistant discovery: %v", err)
+		}
+	}
+}
+
+func (p *Printer) OnException(err error) {
+	p.logger.Error("Moonraker exception: %v", err)
+}
+
+func (p *Printer) availabilityTopic() string {
+	return fmt.Sprintf("%s/availability", p.TopicPrefix())
+}
+
+func (p *Printer) publishAvailability(available bool) {
+	if p.paused.Load() || !p.currentMQTTConfig().AvailabilityEnabled {
+		return
+	}
+
+	payload := mqtt.AVAILABILITY_OFFLINE
+	if available {
+		payload = mqtt.AVAILABILITY_ONLINE
+	}
+
+	p.availabilityMux.Lock()
+	defer p.availabilityMux.Unlock()
+
+	if payload == p.availability || !p.mqttClient.IsConnected() {
+		return
+	}
+
+	if err := p.mqttClient.Publish(p.availabilityTopic(), []byte(payload), p.currentMQTTConfig().QoS, true, 3); err != nil {
+		p.logger.Error("Failed to publish printer availability: %v", err)
+		return
+	}
+
+	p.availability = payload
+}
+
+func (p *Printer) publishConsole(params any) {
+	if p.paused.Load() {
+		return
+	}
+
+	if !p.mqttClient.IsConnected() {
+		p.logger.Warn("Cannot publish console output - MQTT not connected")
+		return
+	}
+
+	topic := fmt.Sprintf("%s/console", p.TopicPrefix())
+	for _, line := range moonraker.ParseGcodeResponse(params) {
+		if err := p.mqttClient.Publish(topic, []byte(line), p.currentMQTTConfig().QoS, false, 3); err != nil {
+			p.logger.Error("Failed to publish console line: %v", err)
+		}
+	}
+}
+
+func (p *Printer) handleStatusUpdate(params any) {
+	status, ok := moonraker.ParseStatusUpdate(params)
+	if !ok {
+		p.logger.Warn("Received malformed status update: %v", params)
+		return
+	}
+
+	updated := p.objectCache.Merge(status)
+	if err := p.processUpdates(updated); err != nil {
+		p.logger.Error("Failed to publish status update: %v", err)
+	}
+}
+
+func (p *Printer) publishInitialInfo(ctx context.Context) error {
+	if p.paused.Load() {
+		return nil
+	}
+
+	mqttConfig := p.currentMQTTConfig()
+
+	serverInfo, err := p.client.GetServerInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get server info: %w", err)
+	}
+
+	data, err := json.Marshal(serverInfo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal server info: %w", err)
+	}
+
+	topic := fmt.Sprintf("%s/server/info", p.TopicPrefix())
+	if err := p.mqttClient.Publish(topic, data, mqttConfig.QoS, mqttConfig.Retain, 3); err != nil {
+		return fmt.Errorf("failed to publish server info: %w", err)
+	}
+
+	printerInfo, err := p.client.GetHostInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get printer info: %w", err)
+	}
+
+	data, err = json.Marshal(printerInfo)
+	if err != nil {
+		return fmt.Errorf("failed to marshal printer info: %w", err)
+	}
+
+	topic = fmt.Sprintf("%s/printer/info", p.TopicPrefix())
+	if err := p.mqttClient.Publish(topic, data, mqttConfig.QoS, mqttConfig.Retain, 3); err != nil {
+		return fmt.Errorf("failed to publish printer info: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Printer) periodicMonitoring(ctx context.Context) {
+	callInterval := p.CallInterval()
+	ticker := time.NewTicker(callInterval)
+	defer ticker.Stop()
+
+	consecutiveErrors := 0
+	maxConsecutiveErrors := 5
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.intervalChanged:
+			callInterval = p.CallInterval()
+			consecutiveErrors = 0
+			ticker.Reset(callInterval)
+		case <-ticker.C:
+			mqttConnected := p.mqttClient.IsConnected()
+			moonrakerConnected := p.client.IsConnected()
+
+			if !mqttConnected || !moonrakerConnected {
+				consecutiveErrors++
+				if consecutiveErrors <= 5 {
+					p.logger.Warn("Skipping status publication - MQTT=%t, Moonraker=%t", mqttConnected, moonrakerConnected)
+				}
+				continue
+			}
+
+			if err := p.publishStatus(ctx); err != nil {
+				consecutiveErrors++
+				p.logger.Error("Failed to publish periodic status (error %d/%d): %v", consecutiveErrors, maxConsecutiveErrors, err)
+
+				if consecutiveErrors >= maxConsecutiveErrors {
+					p.logger.Warn("Too many consecutive errors, slowing down polling interval")
+					ticker.Reset(callInterval * 2)
+				}
+			} else {
+				if consecutiveErrors > 0 {
+					p.logger.Info("Successfully published status after %d errors, resuming normal polling", consecutiveErrors)
+					consecutiveErrors = 0
+					ticker.Reset(callInterval)
+				}
+			}
+		}
+	}
+}
+
+func (p *Printer) publishStatus(ctx context.Context) error {
+	if p.subscribed.Load() && !p.currentConfig().PollingFallback {
+		return p.publishSubscribedStatus()
+	}
+
+	klippyState, err := p.client.GetKlippyState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get klipper state: %w", err)
+	}
+
+	if err := p.publishKlippyState(klippyState); err != nil {
+		return err
+	}
+
+	if !p.subscribed.Load() && klippyState == KLIPPY_STATE_READY {
+		if err := p.subscribeObjects(ctx); err != nil {
+			p.logger.Warn("Failed to subscribe to monitored objects, polling instead: %v", err)
+		} else {
+			p.logger.Info("Subscribed to monitored objects")
+			if !p.currentConfig().PollingFallback {
+				return nil
+			}
+		}
+	}
+
+	result, err := p.client.QueryObjects(ctx, p.subscriptionObjects())
+	if err != nil {
+		return fmt.Errorf("failed to query objects: %w", err)
+	}
+
+	updated := p.objectCache.Merge(result)
+	return p.processUpdates(updated)
+}
+
+func (p *Printer) publishSubscribedStatus() error {
+	if !p.filter.Enabled() {
+		return nil
+	}
+
+	if klippyState := p.KlippyState(); klippyState != "" {
+		if err := p.publishKlippyState(klippyState); err != nil {
+			return err
+		}
+	}
+
+	return p.publishObjects(p.objectCache.Names())
+}
+
+func (p *Printer) subscribeObjects(ctx context.Context) error {
+	p.objectCache.Reset()
+	p.filter.Reset()
+
+	if p.telemetryPatterns() != nil {
+		objectNames, err := p.client.GetSupportedObjects(ctx)
+		if err != nil {
+			p.logger.Warn("Failed to list printer objects, telemetry objects will not be subscribed: %v", err)
+		}
+
+		p.settingsMux.Lock()
+		p.objectNames = objectNames
+		p.settingsMux.Unlock()
+	}
+
+	status, err := p.client.SubscribeObjects(ctx, p.subscriptionObjects())
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to objects: %w", err)
+	}
+
+	p.subscribed.Store(true)
+
+	updated := p.objectCache.Merge(status)
+	return p.processUpdates(updated)
+}
+
+func (p *Printer) subscriptionObjects() map[string]any {
+	objects := p.monitoredObjects()
+	for objectName, fields := range requiredObjects {
+		objects[objectName] = fields
+	}
+
+	p.settingsMux.Lock()
+	telemetry, objectNames := p.telemetry, p.objectNames
+	p.settingsMux.Unlock()
+
+	for _, objectName := range objectNames {
+		for pattern, fields := range telemetry {
+			if matched, _ := path.Match(pattern, objectName); matched {
+				current, exists := objects[objectName]
+				objects[objectName] = mergeFields(current, exists, fields)
+			}
+		}
+	}
+	return objects
+}
+
+func (p *Printer) telemetryPatterns() map[string][]string {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+	return p.telemetry
+}
+
+func mergeFields(current any, exists bool, fields []string) any {
+	if !exists {
+		return fields
+	}
+
+	names, ok := fieldNames(current)
+	if !ok {
+		return current
+	}
+
+	names = slices.Clone(names)
+	for _, field := range fields {
+		if !slices.Contains(names, field) {
+			names = append(names, field)
+		}
+	}
+	return names
+}
+
+func (p *Printer) processUpdates(updated []string) error {
+	progressUpdated := false
+	for _, objectName := range updated {
+		switch objectName {
+		case "print_stats":
+			if printStats, exists := p.objectCache.Get(objectName); exists {
+				p.publishJobEvents(p.job.UpdatePrintStats(printStats, time.Now()))
+			}
+			progressUpdated = true
+		case "virtual_sdcard", "display_status":
+			progressUpdated = true
+		}
+	}
+
+	if !p.mqttClient.IsConnected() {
+		p.logger.Warn("Cannot publish status update - MQTT not connected")
+		return nil
+	}
+
+	if progressUpdated && !p.paused.Load() {
+		p.publishProgress(time.Now())
+	}
+
+	return p.publishObjects(updated)
+}
+
+func (p *Printer) monitoredObjects() map[string]any {
+	p.settingsMux.Lock()
+	defer p.settingsMux.Unlock()
+
+	if p.monitored != nil {
+		return maps.Clone(p.monitored)
+	}
+
+	objects, err := p.config.GetMonitoredObjects()
+	if err != nil {
+		p.logger.Warn("Failed to get monitored objects from config, using defaults: %v", err)
+		objects = map[string]any{
+			"print_stats": nil,
+			"toolhead":    []string{"position"},
+			"extruder":    []string{"temperature", "target"},
+			"heater_bed":  []string{"temperature", "target"},
+		}
+	}
+	return objects
+}
+
+func (p *Printer) publishObjects(objectNames []string) error {
+	if p.paused.Load() {
+		return nil
+	}
+
+	monitored := p.monitoredObjects()
+	errorCount := 0
+	totalObjects := len(objectNames)
+	now := time.Now()
+	for _, objectName := range objectNames {
+		fields, isMonitored := monitored[objectName]
+		if !isMonitored {
+			continue
+		}
+
+		objectData, exists := p.objectCache.Get(objectName)
+		if !exists {
+			continue
+		}
+		objectData = selectFields(objectData, fields)
+
+		topic := fmt.Sprintf("%s/objects/%s", p.TopicPrefix(), objectName)
+		p.publishFlattened(topic, objectName, objectData, now)
+
+		if !p.filter.ShouldPublish(topic, objectName, objectData, now) {
+			continue
+		}
+
+		data, err := json.Marshal(objectData)
+		if err != nil {
+			p.logger.Error("Failed to marshal object %s: %v", objectName, err)
+			errorCount++
+			continue
+		}
+
+		if err := p.mqttClient.Publish(topic, data, p.currentMQTTConfig().QoS, false, 3); err != nil {
+			p.logger.Error("Failed to publish object %s after retries: %v", objectName, err)
+			errorCount++
+			continue
+		}
+
+		p.filter.Record(topic, objectData, now)
+	}
+
+	if errorCount > 0 {
+		p.logger.Warn("Published objects with %d/%d errors", errorCount, totalObjects)
+		if errorCount >= totalObjects/2 {
+			return fmt.Errorf("too many object publication failures (%d/%d)", errorCount, totalObjects)
+		}
+	}
+
+	return nil
+}
+
+func (p *Printer) publishFlattened(objectTopic, objectName string, objectData map[string]any, now time.Time) {
+	for _, field := range flattenObject(objectData, p.currentPublishConfig().GetFlattenMode(objectName)) {
+		topic := fmt.Sprintf("%s/%s", objectTopic, field.path)
+		if !p.filter.ShouldPublishField(topic, objectName, field.field, field.value, now) {
+			continue
+		}
+
+		if err := p.mqttClient.Publish(topic, []byte(formatScalar(field.value)), p.currentMQTTConfig().QoS, false, 3); err != nil {
+			p.logger.Error("Failed to publish field %s of object %s: %v", field.path, objectName, err)
+			continue
+		}
+
+		p.filter.Record(topic, field.value, now)
+	}
+}
+
+func (p *Printer) publishJobEvents(events []JobEvent, changed bool) {
+	if p.paused.Load() || !p.mqttClient.IsConnected() {
+		return
+	}
+
+	for _, event := range events {
+		p.logger.Info("Print job %s: %s", event.Event, event.Filename)
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			p.logger.Error("Failed to marshal job event: %v", err)
+			continue
+		}
+
+		topic := fmt.Sprintf("%s/job/%s", p.TopicPrefix(), event.Event)
+		if err := p.mqttClient.Publish(topic, data, p.currentMQTTConfig().QoS, false, 3); err != nil {
+			p.logger.Error("Failed to publish job event %s: %v", event.Event, err)
+		}
+	}
+
+	if changed {
+		p.publishCurrentJob()
+	}
+}
+
+func (p *Printer) publishCurrentJob() {
+	current := p.job.Current()
+	if current == nil {
+		return
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		p.logger.Error("Failed to marshal current job: %v", err)
+		return
+	}
+
+	topic := fmt.Sprintf("%s/job/current", p.TopicPrefix())
+	if err := p.mqttClient.Publish(topic, data, p.currentMQTTConfig().QoS, true, 3); err != nil {
+		p.logger.Error("Failed to publish current job: %v", err)
+	}
+}
+
+func fieldNames(fields any) ([]string, bool) {
+	var names []string
+	switch v := fields.(type) {
+	case []string:
+		names = v
+	case []any:
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+	default:
+		return nil, false
+	}
+	return names, true
+}
+
+func selectFields(objectData map[string]any, fields any) map[string]any {
+	names, ok := fieldNames(fields)
+	if !ok {
+		return objectData
+	}
+
+	selected := make(map[string]any, len(names))
+	for _, name := range names {
+		if value, exists := objectData[name]; exists {
+			selected[name] = value
+		}
+	}
+	return selected
+}
+
+func (p *Printer) publishProgress(now time.Time) {
+	printStats, exists := p.objectCache.Get("print_stats")
+	if !exists {
+		return
+	}
+
+	state, _ := printStats["state"].(string)
+	if !isActivePrintState(state) && state != PRINT_STATE_COMPLETE {
+		return
+	}
+
+	filename, _ := printStats["filename"].(string)
+	metadata := p.fileMetadata(filename)
+	virtualSdcard, _ := p.objectCache.Get("virtual_sdcard")
+	displayStatus, _ := p.objectCache.Get("display_status")
+
+	progress := estimateProgress(p.currentPublishConfig().GetProgressMethod(), printStats, virtualSdcard, displayStatus, metadata)
+
+	percent := math.Round(progress.Progress*1000) / 10
+	p.publishJobValue("progress", []byte(strconv.FormatFloat(percent, 'f', -1, 64)), percent, now)
+
+	if progress.HasRemaining {
+		remaining := int64(math.Round(progress.RemainingSeconds))
+		eta := now.Add(time.Duration(remaining) * time.Second)
+		p.publishJobValue("remaining_seconds", []byte(strconv.FormatInt(remaining, 10)), float64(remaining), now)
+		p.publishJobValue("eta", []byte(eta.Format(time.RFC3339)), float64(eta.Unix()), now)
+	}
+
+	if progress.Layer.Current > 0 || progress.Layer.Total > 0 {
+		data, err := json.Marshal(progress.Layer)
+		if err != nil {
+			p.logger.Error("Failed to marshal job layer: %v", err)
+			return
+		}
+		p.publishJobValue("layer", data, progress.Layer, now)
+	}
+}
+
+func (p *Printer) publishJobValue(field string, payload []byte, value any, now time.Time) {
+	topic := fmt.Sprintf("%s/job/%s", p.TopicPrefix(), field)
+	if !p.filter.ShouldPublishField(topic, "job", field, value, now) {
+		return
+	}
+
+	if err := p.mqttClient.Publish(topic, payload, p.currentMQTTConfig().QoS, false, 3); err != nil {
+		p.logger.Error("Failed to publish job %s: %v", field, err)
+		return
+	}
+
+	p.filter.Record(topic, value, now)
+}
+
+func (p *Printer) fileMetadata(filename string) *websocket.FileMetadata {
+	p.metadataMux.Lock()
+	defer p.metadataMux.Unlock()
+
+	if filename == "" {
+		return nil
+	}
+
+	if filename != p.metadataFile {
+		p.metadataFile = filename
+		p.metadata = nil
+		go p.loadFileMetadata(filename)
+	}
+
+	return p.metadata
+}
+
+func (p *Printer) loadFileMetadata(filename string) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.currentConfig().GetTimeout())
+	defer cancel()
+
+	metadata, err := p.client.GetFileMetadata(ctx, filename)
+	if err != nil {
+		p.logger.Warn("Failed to get metadata for %s: %v", filename, err)
+		return
+	}
+
+	p.metadataMux.Lock()
+	defer p.metadataMux.Unlock()
+
+	if p.metadataFile == filename {
+		p.metadata = metadata
+	}
+}
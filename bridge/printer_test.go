@@ -0,0 +1,187 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/homeassistant"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/moonraker"
+	"moonraker2mqtt/mqtt"
+)
+
+type testLogger struct{}
+
+func (testLogger) Debug(format string, args ...any)               {}
+func (testLogger) Info(format string, args ...any)                {}
+func (testLogger) Warn(format string, args ...any)                {}
+func (testLogger) Error(format string, args ...any)               {}
+func (testLogger) SetLevel(level logger.LogLevel)                 {}
+func (testLogger) GetLevel() logger.LogLevel                      { return logger.DEBUG }
+func (l testLogger) With(fields ...any) logger.Logger             { return l }
+func (l testLogger) WithComponent(component string) logger.Logger { return l }
+
+type fakeMQTTClient struct {
+	published    []mqtt.StatusMessage
+	disconnected bool
+	mux          sync.Mutex
+}
+
+func (c *fakeMQTTClient) Connect() error    { return nil }
+func (c *fakeMQTTClient) Disconnect() error { return nil }
+
+func (c *fakeMQTTClient) IsConnected() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return !c.disconnected
+}
+
+func (c *fakeMQTTClient) Publish(topic string, payload []byte, qos byte, retain bool, maxRetries int) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.published = append(c.published, mqtt.StatusMessage{Topic: topic, Payload: payload, QoS: qos, Retain: retain})
+	return nil
+}
+
+func (c *fakeMQTTClient) Subscribe(topic string, handler mqtt.MessageHandler) error { return nil }
+func (c *fakeMQTTClient) Unsubscribe(topic string) error                            { return nil }
+
+func (c *fakeMQTTClient) last(topic string) (string, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for i := len(c.published) - 1; i >= 0; i-- {
+		if c.published[i].Topic == topic {
+			return string(c.published[i].Payload), true
+		}
+	}
+	return "", false
+}
+
+func newTestPrinter(mqttClient mqtt.MQTTClient) *Printer {
+	publishConfig := &config.PublishConfig{OnlyOnChange: true}
+	return &Printer{
+		config:        &config.PrinterConfig{Name: "voron"},
+		mqttConfig:    &config.MQTTConfig{AvailabilityEnabled: true},
+		publishConfig: publishConfig,
+		topicPrefix:   "moonraker",
+		objectCache:   moonraker.NewObjectCache(),
+		mqttClient:    mqttClient,
+		filter:        newChangeFilter(publishConfig),
+		job:           newJobTracker(),
+		logger:        testLogger{},
+	}
+}
+
+func TestPrinter_KlippyNotifications(t *testing.T) {
+	tests := []struct {
+		method           string
+		wantState        string
+		wantAvailability string
+	}{
+		{"notify_klippy_shutdown", KLIPPY_STATE_SHUTDOWN, mqtt.AVAILABILITY_OFFLINE},
+		{"notify_klippy_disconnected", KLIPPY_STATE_DISCONNECTED, mqtt.AVAILABILITY_OFFLINE},
+		{"notify_klippy_ready", KLIPPY_STATE_READY, mqtt.AVAILABILITY_ONLINE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			mqttClient := &fakeMQTTClient{}
+			printer := newTestPrinter(mqttClient)
+			printer.subscribed.Store(true)
+
+			printer.OnNotification(tt.method, nil)
+
+			if state, ok := mqttClient.last("moonraker/klipper/state"); !ok || state != tt.wantState {
+				t.Errorf("klipper/state = %q (published %t), want %q", state, ok, tt.wantState)
+			}
+			if availability, ok := mqttClient.last("moonraker/availability"); !ok || availability != tt.wantAvailability {
+				t.Errorf("availability = %q (published %t), want %q", availability, ok, tt.wantAvailability)
+			}
+			if _, ok := mqttClient.last("moonraker/notifications/" + tt.method); !ok {
+				t.Errorf("notification %s was not forwarded", tt.method)
+			}
+			if subscribed := printer.subscribed.Load(); subscribed != (tt.wantState == KLIPPY_STATE_READY) {
+				t.Errorf("subscribed = %t after %s", subscribed, tt.method)
+			}
+		})
+	}
+}
+
+func TestPrinter_PublishStatusWhileSubscribed(t *testing.T) {
+	mqttClient := &fakeMQTTClient{}
+	printer := newTestPrinter(mqttClient)
+	printer.objectCache = moonraker.NewObjectCache()
+	printer.subscribed.Store(true)
+
+	printer.OnNotification("notify_klippy_ready", nil)
+	printer.subscribed.Store(true)
+	mqttClient.published = nil
+
+	// The printer has no Moonraker client, so any RPC would panic.
+	if err := printer.publishStatus(context.Background()); err != nil {
+		t.Fatalf("publishStatus() error = %v", err)
+	}
+
+	if _, ok := mqttClient.last("moonraker/klipper/state"); ok {
+		t.Errorf("klipper/state was republished before its heartbeat")
+	}
+}
+
+func TestPrinter_StatusUpdateWhileMQTTDisconnected(t *testing.T) {
+	mqttClient := &fakeMQTTClient{disconnected: true}
+	printer := newTestPrinter(mqttClient)
+	printer.objectCache = moonraker.NewObjectCache()
+
+	printer.OnNotification("notify_status_update", []any{map[string]any{
+		"print_stats": map[string]any{"state": PRINT_STATE_PRINTING, "filename": "benchy.gcode"},
+	}})
+	if len(mqttClient.published) != 0 {
+		t.Errorf("published %d message(s) while MQTT was disconnected", len(mqttClient.published))
+	}
+
+	current := printer.job.Current()
+	if current == nil || current.State != PRINT_STATE_PRINTING {
+		t.Fatalf("job tracker did not follow print_stats while MQTT was disconnected: %+v", current)
+	}
+}
+
+func TestPrinter_DiscoveryNodeID(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodeID   string
+		printers []config.PrinterConfig
+		want     string
+	}{
+		{"single printer uses hostname", "", nil, "/mainsailos/"},
+		{"single printer uses node id", "farm", nil, "/farm/"},
+		{"multiple printers use printer name", "", []config.PrinterConfig{{Name: "voron"}, {Name: "ender"}}, "/voron/"},
+		{"multiple printers prefix node id", "farm", []config.PrinterConfig{{Name: "voron"}, {Name: "ender"}}, "/farm_voron/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printer := newTestPrinter(&fakeMQTTClient{})
+			cfg := &config.Config{
+				Printers:      tt.printers,
+				HomeAssistant: config.HomeAssistantConfig{Enabled: true, DiscoveryPrefix: "homeassistant", NodeID: tt.nodeID},
+			}
+
+			messages, err := printer.newDiscovery(cfg).Messages(&homeassistant.PrinterDescription{Hostname: "mainsailos"})
+			if err != nil {
+				t.Fatalf("Messages() error = %v", err)
+			}
+			if len(messages) == 0 {
+				t.Fatal("Messages() returned no discovery configs")
+			}
+			for _, message := range messages {
+				if !strings.Contains(message.Topic, tt.want) {
+					t.Errorf("topic %s does not contain node %s", message.Topic, tt.want)
+				}
+			}
+		})
+	}
+}
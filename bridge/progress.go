@@ -0,0 +1,93 @@
+package bridge
+
+import (
+	"math"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/websocket"
+)
+
+func estimateProgress(method string, printStats, virtualSdcard, displayStatus map[string]any, metadata *websocket.FileMetadata) JobProgress {
+	printDuration, _ := toFloat(printStats["print_duration"])
+	result := JobProgress{
+		Progress: fileProgress(virtualSdcard, displayStatus, metadata),
+		Layer:    jobLayer(printStats, metadata),
+	}
+
+	if state, _ := printStats["state"].(string); state == PRINT_STATE_COMPLETE {
+		result.Progress = 1
+		result.HasRemaining = true
+		if result.Layer.Total > 0 {
+			result.Layer.Current = result.Layer.Total
+		}
+		return result
+	}
+
+	switch method {
+	case config.PROGRESS_METHOD_SLICER:
+		if metadata != nil && metadata.EstimatedTime > 0 {
+			result.Progress = clampProgress(printDuration / metadata.EstimatedTime)
+			result.RemainingSeconds = math.Max(metadata.EstimatedTime-printDuration, 0)
+			result.HasRemaining = true
+			return result
+		}
+	case config.PROGRESS_METHOD_FILAMENT:
+		filamentUsed, _ := toFloat(printStats["filament_used"])
+		if metadata != nil && metadata.FilamentTotal > 0 {
+			result.Progress = clampProgress(filamentUsed / metadata.FilamentTotal)
+		}
+	}
+
+	if result.Progress > 0 && printDuration > 0 {
+		result.RemainingSeconds = math.Max(printDuration/result.Progress-printDuration, 0)
+		result.HasRemaining = true
+	}
+
+	return result
+}
+
+func fileProgress(virtualSdcard, displayStatus map[string]any, metadata *websocket.FileMetadata) float64 {
+	if position, ok := toFloat(virtualSdcard["file_position"]); ok && metadata != nil && metadata.GcodeEndByte > metadata.GcodeStartByte {
+		start := float64(metadata.GcodeStartByte)
+		end := float64(metadata.GcodeEndByte)
+		return clampProgress((position - start) / (end - start))
+	}
+
+	if progress, ok := toFloat(virtualSdcard["progress"]); ok {
+		return clampProgress(progress)
+	}
+
+	if progress, ok := toFloat(displayStatus["progress"]); ok {
+		return clampProgress(progress)
+	}
+
+	return 0
+}
+
+func jobLayer(printStats map[string]any, metadata *websocket.FileMetadata) JobLayer {
+	var layer JobLayer
+
+	if info, ok := printStats["info"].(map[string]any); ok {
+		if current, ok := toFloat(info["current_layer"]); ok {
+			layer.Current = int(current)
+		}
+		if total, ok := toFloat(info["total_layer"]); ok {
+			layer.Total = int(total)
+		}
+	}
+
+	if layer.Total == 0 && metadata != nil {
+		switch {
+		case metadata.LayerCount > 0:
+			layer.Total = metadata.LayerCount
+		case metadata.LayerHeight > 0 && metadata.ObjectHeight > 0:
+			layer.Total = int(math.Ceil((metadata.ObjectHeight-metadata.FirstLayerHeight)/metadata.LayerHeight)) + 1
+		}
+	}
+
+	return layer
+}
+
+func clampProgress(progress float64) float64 {
+	return math.Min(math.Max(progress, 0), 1)
+}
@@ -0,0 +1,130 @@
+package bridge
+
+import (
+	"testing"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/websocket"
+)
+
+func TestEstimateProgress(t *testing.T) {
+	metadata := &websocket.FileMetadata{
+		EstimatedTime:    4000,
+		FilamentTotal:    10000,
+		GcodeStartByte:   1000,
+		GcodeEndByte:     11000,
+		LayerHeight:      0.2,
+		FirstLayerHeight: 0.2,
+		ObjectHeight:     20,
+	}
+	printStats := map[string]any{
+		"state":          PRINT_STATE_PRINTING,
+		"print_duration": 1000.0,
+		"filament_used":  2000.0,
+	}
+	virtualSdcard := map[string]any{
+		"progress":      0.3,
+		"file_position": 3500.0,
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		printStats    map[string]any
+		virtualSdcard map[string]any
+		displayStatus map[string]any
+		metadata      *websocket.FileMetadata
+		wantProgress  float64
+		wantRemaining float64
+		wantLayer     JobLayer
+	}{
+		{
+			name:          "file position relative to gcode bytes",
+			method:        config.PROGRESS_METHOD_FILE,
+			printStats:    printStats,
+			virtualSdcard: virtualSdcard,
+			metadata:      metadata,
+			wantProgress:  0.25,
+			wantRemaining: 3000,
+			wantLayer:     JobLayer{Current: 0, Total: 100},
+		},
+		{
+			name:          "file without metadata falls back to virtual_sdcard",
+			method:        config.PROGRESS_METHOD_FILE,
+			printStats:    printStats,
+			virtualSdcard: virtualSdcard,
+			wantProgress:  0.3,
+			wantRemaining: 1000/0.3 - 1000,
+		},
+		{
+			name:          "file falls back to display_status",
+			method:        config.PROGRESS_METHOD_FILE,
+			printStats:    printStats,
+			displayStatus: map[string]any{"progress": 0.5},
+			wantProgress:  0.5,
+			wantRemaining: 1000,
+		},
+		{
+			name:          "slicer estimate",
+			method:        config.PROGRESS_METHOD_SLICER,
+			printStats:    printStats,
+			virtualSdcard: virtualSdcard,
+			metadata:      metadata,
+			wantProgress:  0.25,
+			wantRemaining: 3000,
+			wantLayer:     JobLayer{Current: 0, Total: 100},
+		},
+		{
+			name:          "filament based",
+			method:        config.PROGRESS_METHOD_FILAMENT,
+			printStats:    printStats,
+			virtualSdcard: virtualSdcard,
+			metadata:      metadata,
+			wantProgress:  0.2,
+			wantRemaining: 4000,
+			wantLayer:     JobLayer{Current: 0, Total: 100},
+		},
+		{
+			name:   "layers from print_stats info",
+			method: config.PROGRESS_METHOD_FILE,
+			printStats: map[string]any{
+				"state":          PRINT_STATE_PRINTING,
+				"print_duration": 1000.0,
+				"info":           map[string]any{"current_layer": 45.0, "total_layer": 120.0},
+			},
+			virtualSdcard: virtualSdcard,
+			metadata:      metadata,
+			wantProgress:  0.25,
+			wantRemaining: 3000,
+			wantLayer:     JobLayer{Current: 45, Total: 120},
+		},
+		{
+			name:          "completed print",
+			method:        config.PROGRESS_METHOD_SLICER,
+			printStats:    map[string]any{"state": PRINT_STATE_COMPLETE, "print_duration": 3900.0},
+			metadata:      metadata,
+			wantProgress:  1,
+			wantRemaining: 0,
+			wantLayer:     JobLayer{Current: 100, Total: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateProgress(tt.method, tt.printStats, tt.virtualSdcard, tt.displayStatus, tt.metadata)
+
+			if diff := got.Progress - tt.wantProgress; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Progress = %v, want %v", got.Progress, tt.wantProgress)
+			}
+			if !got.HasRemaining {
+				t.Errorf("HasRemaining = false, want true")
+			}
+			if diff := got.RemainingSeconds - tt.wantRemaining; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("RemainingSeconds = %v, want %v", got.RemainingSeconds, tt.wantRemaining)
+			}
+			if got.Layer != tt.wantLayer {
+				t.Errorf("Layer = %+v, want %+v", got.Layer, tt.wantLayer)
+			}
+		})
+	}
+}
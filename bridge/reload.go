@@ -0,0 +1,116 @@
+package bridge
+
+import (
+	"fmt"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func (p *Printer) Reload(printerConfig *config.PrinterConfig, cfg *config.Config, diff config.PrinterDiff) error {
+	if diff.Connection {
+		p.logger.Info("Moonraker connection settings changed, reconnecting")
+		if err := p.client.Reconfigure(&printerConfig.MoonrakerConfig); err != nil {
+			return fmt.Errorf("failed to reconfigure Moonraker client: %w", err)
+		}
+	}
+
+	p.settingsMux.Lock()
+	p.config = printerConfig
+	p.settingsMux.Unlock()
+
+	if diff.Settings {
+		p.applySettings(cfg)
+	}
+
+	if diff.Telemetry {
+		telemetry := telemetryObjects(&cfg.HTTP)
+
+		p.settingsMux.Lock()
+		p.telemetry = telemetry
+		p.settingsMux.Unlock()
+	}
+
+	if diff.CallInterval {
+		p.SetCallInterval(time.Duration(printerConfig.CallInterval) * time.Second)
+	}
+
+	if diff.Topic {
+		p.setTopicPrefix(printerConfig.GetTopicPrefix(cfg.MQTT.TopicPrefix), cfg)
+	}
+
+	if diff.Settings {
+		discovery := p.newDiscovery(cfg)
+
+		p.settingsMux.Lock()
+		p.discovery = discovery
+		p.settingsMux.Unlock()
+	}
+
+	if diff.MonitoredObjects {
+		objects, err := printerConfig.GetMonitoredObjects()
+		if err != nil {
+			return fmt.Errorf("invalid monitored objects: %w", err)
+		}
+		p.SetMonitoredObjects(objects)
+	} else if diff.Topic || diff.Settings || diff.Telemetry {
+		p.Republish()
+	}
+
+	return nil
+}
+
+func (p *Printer) setTopicPrefix(topicPrefix string, cfg *config.Config) {
+	if topicPrefix == p.TopicPrefix() {
+		return
+	}
+
+	p.UnsubscribeCommands()
+
+	p.settingsMux.Lock()
+	p.topicPrefix = topicPrefix
+	p.settingsMux.Unlock()
+
+	discovery := p.newDiscovery(cfg)
+
+	p.settingsMux.Lock()
+	p.discovery = discovery
+	p.settingsMux.Unlock()
+
+	p.availabilityMux.Lock()
+	p.availability = ""
+	p.availabilityMux.Unlock()
+
+	p.filter.Reset()
+	p.SubscribeCommands()
+
+	p.logger.Info("Topic prefix changed to %s", topicPrefix)
+}
+
+func (p *Printer) applySettings(cfg *config.Config) {
+	previous := p.currentMQTTConfig()
+	commandsChanged := previous.CommandsEnabled != cfg.MQTT.CommandsEnabled || previous.EstopEnabled != cfg.MQTT.EstopEnabled
+	if commandsChanged {
+		p.UnsubscribeCommands()
+	}
+
+	p.settingsMux.Lock()
+	p.mqttConfig = &cfg.MQTT
+	p.publishConfig = &cfg.Publish
+	p.settingsMux.Unlock()
+
+	p.filter.SetConfig(&cfg.Publish)
+	p.client.SetRPCFilter(cfg.MQTT.IsRPCMethodAllowed)
+
+	if previous.AvailabilityEnabled != cfg.MQTT.AvailabilityEnabled {
+		p.availabilityMux.Lock()
+		p.availability = ""
+		p.availabilityMux.Unlock()
+	}
+
+	if commandsChanged {
+		p.SubscribeCommands()
+	}
+
+	p.logger.Info("Publish and MQTT settings updated")
+}
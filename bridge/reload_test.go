@@ -0,0 +1,111 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/moonraker"
+)
+
+func TestPrinter_Reload(t *testing.T) {
+	tests := []struct {
+		name         string
+		diff         config.PrinterDiff
+		wantInterval time.Duration
+		wantObjects  int
+		wantTopic    string
+	}{
+		{
+			name:         "call interval",
+			diff:         config.PrinterDiff{CallInterval: true},
+			wantInterval: 5 * time.Second,
+			wantObjects:  1,
+			wantTopic:    "moonraker",
+		},
+		{
+			name:         "monitored objects",
+			diff:         config.PrinterDiff{MonitoredObjects: true},
+			wantInterval: 2 * time.Second,
+			wantObjects:  2,
+			wantTopic:    "moonraker",
+		},
+		{
+			name:         "topic",
+			diff:         config.PrinterDiff{Topic: true},
+			wantInterval: 2 * time.Second,
+			wantObjects:  1,
+			wantTopic:    "printers/voron",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printer := newTestPrinter(&fakeMQTTClient{})
+			printer.callInterval = 2 * time.Second
+			printer.monitored = map[string]any{"print_stats": nil}
+
+			cfg := &config.Config{MQTT: config.MQTTConfig{TopicPrefix: "printers"}}
+			printerConfig := &config.PrinterConfig{
+				Name:  "voron",
+				Topic: "voron",
+				MoonrakerConfig: config.MoonrakerConfig{
+					CallInterval:     5,
+					MonitoredObjects: `{"print_stats": null, "extruder": ["temperature"]}`,
+				},
+			}
+
+			if err := printer.Reload(printerConfig, cfg, tt.diff); err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+
+			if interval := printer.CallInterval(); interval != tt.wantInterval {
+				t.Errorf("call interval = %s, want %s", interval, tt.wantInterval)
+			}
+			if objects := printer.MonitoredObjects(); len(objects) != tt.wantObjects {
+				t.Errorf("monitored objects = %v, want %d object(s)", objects, tt.wantObjects)
+			}
+			if topic := printer.TopicPrefix(); topic != tt.wantTopic {
+				t.Errorf("topic prefix = %s, want %s", topic, tt.wantTopic)
+			}
+		})
+	}
+}
+
+func TestPrinter_ReloadSettings(t *testing.T) {
+	mqttClient := &fakeMQTTClient{}
+	printer := newTestPrinter(mqttClient)
+	printer.client = moonraker.NewClient(&config.MoonrakerConfig{}, testLogger{}, printer)
+	printer.client.SetRPCFilter(printer.mqttConfig.IsRPCMethodAllowed)
+
+	cfg := &config.Config{
+		MQTT:    config.MQTTConfig{TopicPrefix: "moonraker", QoS: 1, AvailabilityEnabled: true, RPCAllow: []string{"server.files.list"}},
+		Publish: config.PublishConfig{OnlyOnChange: false},
+	}
+	printerConfig := &config.PrinterConfig{Name: "voron"}
+
+	if err := printer.Reload(printerConfig, cfg, config.PrinterDiff{Name: "voron", Settings: true}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if printer.filter.Enabled() {
+		t.Error("change filter still enabled after only_on_change was turned off")
+	}
+
+	if err := printer.publishKlippyState(KLIPPY_STATE_READY); err != nil {
+		t.Fatalf("publishKlippyState() error = %v", err)
+	}
+	mqttClient.mux.Lock()
+	for _, message := range mqttClient.published {
+		if message.QoS != 1 {
+			t.Errorf("%s published with QoS %d, want 1", message.Topic, message.QoS)
+		}
+	}
+	mqttClient.mux.Unlock()
+
+	result := printer.client.HandleCommand(context.Background(), []byte(`{"command": "rpc", "params": {"method": "server.files.list"}}`))
+	if result.Error != nil && result.Error.Code == moonraker.COMMAND_ERROR_FORBIDDEN {
+		t.Errorf("rpc method still forbidden after rpc_allow was updated: %+v", result.Error)
+	}
+}
@@ -0,0 +1,209 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/homeassistant"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/moonraker"
+	"moonraker2mqtt/mqtt"
+	"moonraker2mqtt/websocket"
+)
+
+const (
+	LOG_COMPONENT = "bridge"
+
+	COMMAND_QUEUE_SIZE      = 32
+	HEALTH_PUBLISH_INTERVAL = 10 * time.Second
+
+	PRINT_STATE_STANDBY   = "standby"
+	PRINT_STATE_PRINTING  = "printing"
+	PRINT_STATE_PAUSED    = "paused"
+	PRINT_STATE_COMPLETE  = "complete"
+	PRINT_STATE_CANCELLED = "cancelled"
+	PRINT_STATE_ERROR     = "error"
+
+	JOB_EVENT_STARTED   = "started"
+	JOB_EVENT_PAUSED    = "paused"
+	JOB_EVENT_RESUMED   = "resumed"
+	JOB_EVENT_COMPLETED = "completed"
+	JOB_EVENT_CANCELLED = "cancelled"
+	JOB_EVENT_ERROR     = "error"
+
+	KLIPPY_STATE_READY        = "ready"
+	KLIPPY_STATE_SHUTDOWN     = "shutdown"
+	KLIPPY_STATE_DISCONNECTED = "disconnected"
+
+	TELEMETRY_LABEL_PRINTER = "printer"
+	TELEMETRY_LABEL_OBJECT  = "object"
+	TELEMETRY_LABEL_ELEMENT = "element"
+)
+
+var requiredObjects = map[string]any{
+	"print_stats":    nil,
+	"virtual_sdcard": nil,
+	"display_status": nil,
+}
+
+type commandRequest struct {
+	topic   string
+	payload []byte
+}
+
+type Printer struct {
+	config          *config.PrinterConfig
+	mqttConfig      *config.MQTTConfig
+	publishConfig   *config.PublishConfig
+	topicPrefix     string
+	client          *moonraker.Client
+	mqttClient      mqtt.MQTTClient
+	objectCache     *moonraker.ObjectCache
+	filter          *changeFilter
+	job             *jobTracker
+	metadata        *websocket.FileMetadata
+	metadataFile    string
+	metadataMux     sync.Mutex
+	subscribed      atomic.Bool
+	discovery       *homeassistant.Discovery
+	availability    string
+	availabilityMux sync.Mutex
+	klippyState     string
+	klippyMux       sync.Mutex
+	commands        chan commandRequest
+	ctx             context.Context
+	started         atomic.Bool
+	resyncMux       sync.Mutex
+	callInterval    time.Duration
+	monitored       map[string]any
+	telemetry       map[string][]string
+	objectNames     []string
+	settingsMux     sync.Mutex
+	intervalChanged chan struct{}
+	paused          atomic.Bool
+	logger          logger.Logger
+}
+
+type Controller struct {
+	printers      []*Printer
+	mqttClient    mqtt.MQTTClient
+	topicPrefix   string
+	qos           byte
+	logComponents map[string]string
+	root          logger.Logger
+	mux           sync.Mutex
+	logger        logger.Logger
+}
+
+type ControlRequest struct {
+	Printer       string            `json:"printer,omitempty"`
+	LogLevel      string            `json:"log_level,omitempty"`
+	LogComponents map[string]string `json:"log_components,omitempty"`
+	CallInterval  *int              `json:"call_interval,omitempty"`
+	AddObjects    map[string]any    `json:"add_objects,omitempty"`
+	RemoveObjects []string          `json:"remove_objects,omitempty"`
+	Republish     bool              `json:"republish,omitempty"`
+	Paused        *bool             `json:"paused,omitempty"`
+}
+
+type PrinterSettings struct {
+	Name             string         `json:"name"`
+	CallInterval     int            `json:"call_interval"`
+	MonitoredObjects map[string]any `json:"monitored_objects"`
+	Paused           bool           `json:"paused"`
+}
+
+type BridgeConfig struct {
+	LogLevel      string            `json:"log_level"`
+	LogComponents map[string]string `json:"log_components,omitempty"`
+	Printers      []PrinterSettings `json:"printers"`
+}
+
+type Telemetry struct {
+	printers []*Printer
+	rules    []telemetryRule
+}
+
+type telemetryRule struct {
+	key     string
+	pattern string
+	path    []string
+	metric  string
+}
+
+type telemetrySample struct {
+	element string
+	value   float64
+}
+
+type PrinterHealth struct {
+	Name        string   `json:"name"`
+	State       string   `json:"state"`
+	Connected   bool     `json:"connected"`
+	KlippyState string   `json:"klippy_state,omitempty"`
+	LastSeen    string   `json:"last_seen,omitempty"`
+	IdleSeconds *float64 `json:"idle_seconds,omitempty"`
+}
+
+type BridgeHealth struct {
+	MQTTConnected bool              `json:"mqtt_connected"`
+	Printers      []PrinterHealth   `json:"printers"`
+	Links         map[string]string `json:"links,omitempty"`
+	Timestamp     string            `json:"timestamp"`
+}
+
+type publishedValue struct {
+	value any
+	at    time.Time
+}
+
+type changeFilter struct {
+	config    *config.PublishConfig
+	published map[string]publishedValue
+	mux       sync.Mutex
+}
+
+type flatField struct {
+	path  string
+	field string
+	value any
+}
+
+type JobSummary struct {
+	JobID         string  `json:"job_id,omitempty"`
+	Filename      string  `json:"filename"`
+	State         string  `json:"state"`
+	Outcome       string  `json:"outcome,omitempty"`
+	Message       string  `json:"message,omitempty"`
+	StartTime     float64 `json:"start_time,omitempty"`
+	EndTime       float64 `json:"end_time,omitempty"`
+	TotalDuration float64 `json:"total_duration"`
+	PrintDuration float64 `json:"print_duration"`
+	FilamentUsed  float64 `json:"filament_used"`
+}
+
+type JobEvent struct {
+	Event string `json:"event"`
+	JobSummary
+}
+
+type jobTracker struct {
+	state   string
+	current *JobSummary
+	mux     sync.Mutex
+}
+
+type JobLayer struct {
+	Current int `json:"current"`
+	Total   int `json:"total"`
+}
+
+type JobProgress struct {
+	Progress         float64
+	RemainingSeconds float64
+	HasRemaining     bool
+	Layer            JobLayer
+}
@@ -0,0 +1,189 @@
+package bridge
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/metrics"
+)
+
+func NewTelemetry(printers []*Printer, mapping map[string]string) *Telemetry {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	telemetry := &Telemetry{printers: printers}
+	for _, key := range keys {
+		pattern, field, found := strings.Cut(key, ".")
+		if !found {
+			continue
+		}
+		telemetry.rules = append(telemetry.rules, telemetryRule{
+			key:     key,
+			pattern: pattern,
+			path:    strings.Split(field, "."),
+			metric:  mapping[key],
+		})
+	}
+
+	return telemetry
+}
+
+func telemetryObjects(httpConfig *config.HTTPConfig) map[string][]string {
+	if !httpConfig.Enabled || !httpConfig.Telemetry {
+		return nil
+	}
+
+	objects := make(map[string][]string)
+	for key := range httpConfig.GetTelemetryMetrics() {
+		pattern, field, found := strings.Cut(key, ".")
+		if !found {
+			continue
+		}
+		field, _, _ = strings.Cut(field, ".")
+		if !slices.Contains(objects[pattern], field) {
+			objects[pattern] = append(objects[pattern], field)
+		}
+	}
+	return objects
+}
+
+func (t *Telemetry) Register(registry *metrics.Registry) {
+	for _, metric := range t.Metrics() {
+		registry.NewGaugeFunc(metric, t.help(metric), func() []metrics.GaugeValue {
+			return t.collect(metric)
+		}, TELEMETRY_LABEL_PRINTER, TELEMETRY_LABEL_OBJECT, TELEMETRY_LABEL_ELEMENT)
+	}
+}
+
+func (t *Telemetry) Unregister(registry *metrics.Registry) {
+	for _, metric := range t.Metrics() {
+		registry.Unregister(metric)
+	}
+}
+
+func (t *Telemetry) Metrics() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, rule := range t.rules {
+		if !seen[rule.metric] {
+			seen[rule.metric] = true
+			names = append(names, rule.metric)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *Telemetry) help(metric string) string {
+	var keys []string
+	for _, rule := range t.rules {
+		if rule.metric == metric {
+			keys = append(keys, rule.key)
+		}
+	}
+	return fmt.Sprintf("Klipper object fields %s.", strings.Join(keys, ", "))
+}
+
+func (t *Telemetry) collect(metric string) []metrics.GaugeValue {
+	var values []metrics.GaugeValue
+	seen := make(map[string]bool)
+
+	for _, printer := range t.printers {
+		objectNames := printer.objectCache.Names()
+		for _, rule := range t.rules {
+			if rule.metric != metric {
+				continue
+			}
+
+			for _, objectName := range objectNames {
+				if matched, _ := path.Match(rule.pattern, objectName); !matched {
+					continue
+				}
+
+				objectData, exists := printer.objectCache.Get(objectName)
+				if !exists {
+					continue
+				}
+
+				value, exists := lookupField(objectData, rule.path)
+				if !exists {
+					continue
+				}
+
+				for _, sample := range telemetrySamples(value) {
+					labelValues := []string{printer.Name(), objectName, sample.element}
+					key := strings.Join(labelValues, "\xff")
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+
+					values = append(values, metrics.GaugeValue{LabelValues: labelValues, Value: sample.value})
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+func lookupField(objectData map[string]any, fieldPath []string) (any, bool) {
+	var value any = objectData
+	for _, field := range fieldPath {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = fields[field]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func telemetrySamples(value any) []telemetrySample {
+	if items, ok := value.([]any); ok {
+		var samples []telemetrySample
+		for i, item := range items {
+			if number, ok := telemetryValue(item); ok {
+				samples = append(samples, telemetrySample{
+					element: elementName(i, len(items), config.FLATTEN_MODE_AXES),
+					value:   number,
+				})
+			}
+		}
+		return samples
+	}
+
+	if number, ok := telemetryValue(value); ok {
+		return []telemetrySample{{value: number}}
+	}
+	return nil
+}
+
+func telemetryValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
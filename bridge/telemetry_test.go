@@ -0,0 +1,174 @@
+package bridge
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/metrics"
+	"moonraker2mqtt/websocket"
+)
+
+func newTelemetryPrinter(name string, status map[string]any) *Printer {
+	printer := newTestPrinter(&fakeMQTTClient{})
+	printer.config.Name = name
+	printer.objectCache.Merge(status)
+	return printer
+}
+
+func TestTelemetry_Collect(t *testing.T) {
+	voron := newTelemetryPrinter("voron", map[string]any{
+		"extruder":                   map[string]any{"temperature": 210.5, "target": 210.0, "power": 0.4},
+		"heater_bed":                 map[string]any{"temperature": 60.0, "target": 60.0},
+		"toolhead":                   map[string]any{"position": []any{10.0, 20.0, 0.3, 1.5}, "homed_axes": "xyz"},
+		"fan":                        map[string]any{"speed": 0.5},
+		"mcu":                        map[string]any{"last_stats": map[string]any{"mcu_awake": 0.02}},
+		"mcu rpi":                    map[string]any{"last_stats": map[string]any{"mcu_awake": 0.01}},
+		"print_stats":                map[string]any{"state": "printing", "filament_used": 123.4},
+		"temperature_sensor chamber": map[string]any{"temperature": 35.0},
+	})
+	polling := newTelemetryPrinter("prusa", map[string]any{
+		"extruder": map[string]any{"temperature": 40.0},
+	})
+	offline := newTelemetryPrinter("ender", map[string]any{
+		"extruder": map[string]any{"temperature": 25.0},
+	})
+	offline.OnStateChanged(websocket.WEB_SOCKET_STATE_STOPPED)
+
+	telemetry := NewTelemetry([]*Printer{voron, polling, offline}, map[string]string{
+		"extruder*.temperature":            "klipper_temperature_celsius",
+		"heater_bed.temperature":           "klipper_temperature_celsius",
+		"temperature_sensor *.temperature": "klipper_temperature_celsius",
+		"toolhead.position":                "klipper_toolhead_position_mm",
+		"toolhead.homed_axes":              "klipper_homed_axes",
+		"fan.speed":                        "klipper_fan_speed",
+		"mcu*.last_stats.mcu_awake":        "klipper_mcu_awake",
+		"print_stats.filament_used":        "klipper_filament_used_mm",
+	})
+
+	registry := metrics.NewRegistry()
+	telemetry.Register(registry)
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	output := buf.String()
+
+	want := []string{
+		`klipper_temperature_celsius{printer="voron",object="extruder"} 210.5`,
+		`klipper_temperature_celsius{printer="voron",object="heater_bed"} 60`,
+		`klipper_temperature_celsius{printer="voron",object="temperature_sensor chamber"} 35`,
+		`klipper_toolhead_position_mm{printer="voron",object="toolhead",element="x"} 10`,
+		`klipper_toolhead_position_mm{printer="voron",object="toolhead",element="e"} 1.5`,
+		`klipper_fan_speed{printer="voron",object="fan"} 0.5`,
+		`klipper_mcu_awake{printer="voron",object="mcu"} 0.02`,
+		`klipper_mcu_awake{printer="voron",object="mcu rpi"} 0.01`,
+		`klipper_filament_used_mm{printer="voron",object="print_stats"} 123.4`,
+		`klipper_temperature_celsius{printer="prusa",object="extruder"} 40`,
+		"# TYPE klipper_temperature_celsius gauge",
+	}
+	for _, line := range want {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("telemetry output is missing %q\n%s", line, output)
+		}
+	}
+
+	if strings.Contains(output, `printer="ender"`) {
+		t.Errorf("telemetry output contains a disconnected printer\n%s", output)
+	}
+	if strings.Contains(output, "klipper_homed_axes") {
+		t.Errorf("telemetry output contains a non-numeric field\n%s", output)
+	}
+}
+
+func TestTelemetry_Unregister(t *testing.T) {
+	printer := newTelemetryPrinter("voron", map[string]any{
+		"fan": map[string]any{"speed": 1.0},
+	})
+	telemetry := NewTelemetry([]*Printer{printer}, map[string]string{"fan.speed": "klipper_fan_speed"})
+
+	registry := metrics.NewRegistry()
+	telemetry.Register(registry)
+	telemetry.Unregister(registry)
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("WriteText() after Unregister() = %s, want no output", buf.String())
+	}
+}
+
+func TestPrinter_SubscriptionObjectsIncludeTelemetry(t *testing.T) {
+	printer := newTestPrinter(&fakeMQTTClient{})
+	printer.config.MonitoredObjects = `{"extruder":["temperature"],"toolhead":null}`
+	printer.telemetry = telemetryObjects(&config.HTTPConfig{
+		Enabled:          true,
+		Telemetry:        true,
+		TelemetryMetrics: map[string]string{"toolhead.position": ""},
+	})
+	printer.objectNames = []string{"extruder", "toolhead", "mcu", "mcu rpi", "system_stats", "fan"}
+
+	objects := printer.subscriptionObjects()
+
+	tests := []struct {
+		object string
+		want   []string
+	}{
+		{"extruder", []string{"temperature", "target", "power"}},
+		{"mcu", []string{"last_stats"}},
+		{"mcu rpi", []string{"last_stats"}},
+		{"fan", []string{"speed"}},
+	}
+	for _, tt := range tests {
+		fields, ok := objects[tt.object].([]string)
+		if !ok {
+			t.Errorf("subscriptionObjects()[%s] = %v, want fields %v", tt.object, objects[tt.object], tt.want)
+			continue
+		}
+		for _, field := range tt.want {
+			if !slices.Contains(fields, field) {
+				t.Errorf("subscriptionObjects()[%s] = %v, missing %s", tt.object, fields, field)
+			}
+		}
+	}
+
+	if fields, ok := objects["system_stats"].([]string); !ok || len(fields) != 3 {
+		t.Errorf("subscriptionObjects()[system_stats] = %v, want the three default telemetry fields", objects["system_stats"])
+	}
+	if fields := objects["toolhead"]; fields != nil {
+		t.Errorf("subscriptionObjects()[toolhead] = %v, want all fields as monitored", fields)
+	}
+	if fields := printer.monitoredObjects()["extruder"]; len(fields.([]any)) != 1 {
+		t.Errorf("monitoredObjects()[extruder] = %v, want the monitored fields untouched", fields)
+	}
+}
+
+func TestTelemetryValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		want   float64
+		wantOK bool
+	}{
+		{"float", 1.5, 1.5, true},
+		{"int", 3, 3, true},
+		{"true", true, 1, true},
+		{"false", false, 0, true},
+		{"string", "ready", 0, false},
+		{"nil", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := telemetryValue(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("telemetryValue(%v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,31 +2,42 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"moonraker2mqtt/bridge"
 	"moonraker2mqtt/config"
+	"moonraker2mqtt/homeassistant"
 	"moonraker2mqtt/logger"
-	"moonraker2mqtt/moonraker"
+	"moonraker2mqtt/metrics"
 	"moonraker2mqtt/mqtt"
+	"moonraker2mqtt/supervisor"
 	"moonraker2mqtt/version"
 )
 
 const (
-	DEFAULT_CONFIG_FILE = "config.yaml"
+	DEFAULT_CONFIG_FILE   = "config.yaml"
+	LOG_COMPONENT         = "app"
+	CONFIG_WATCH_INTERVAL = 2 * time.Second
 )
 
 type App struct {
-	config          *config.Config
-	moonrakerClient *moonraker.Client
-	mqttClient      mqtt.MQTTClient
-	logger          logger.Logger
+	config     *config.Config
+	configFile string
+	printers   []*bridge.Printer
+	mqttClient *mqtt.PahoClient
+	supervisor *supervisor.Supervisor
+	controller *bridge.Controller
+	telemetry  *bridge.Telemetry
+	reloadMux  sync.Mutex
+	configMux  sync.RWMutex
+	logger     logger.Logger
 }
 
 func NewApp(configFile string) (*App, error) {
@@ -35,10 +46,9 @@ func NewApp(configFile string) (*App, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	logger := logger.New(&cfg.Logging, cfg.Environment)
-
-	if logger == nil {
-		return nil, fmt.Errorf("failed to create logger")
+	logger, err := logger.New(&cfg.Logging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
 	}
 
 	mqttClient := mqtt.NewPahoClient(
@@ -51,56 +61,48 @@ func NewApp(configFile string) (*App, error) {
 		logger,
 	)
 
+	tlsConfig, err := cfg.MQTT.GetTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build MQTT TLS config: %w", err)
+	}
+	mqttClient.SetTLSConfig(tlsConfig)
+
+	setAvailabilityMessages(mqttClient, &cfg.MQTT)
+
 	app := &App{
 		config:     cfg,
+		configFile: configFile,
 		mqttClient: mqttClient,
-		logger:     logger,
+		supervisor: supervisor.New(supervisor.DEFAULT_CHECK_INTERVAL, logger),
+		logger:     logger.WithComponent(LOG_COMPONENT),
 	}
 
-	app.moonrakerClient = moonraker.NewClient(&cfg.Moonraker, logger, app)
-
-	return app, nil
-}
-
-func (a *App) OnStateChanged(state string) {
-	a.logger.Debug("Moonraker state changed: %s", state)
+	app.supervisor.Add(
+		supervisor.NewLink("mqtt", func(ctx context.Context) error { return mqttClient.Connect() }, mqttClient.IsConnected),
+		supervisor.NewPolicy(cfg.MQTT.AutoReconnect, cfg.MQTT.MaxReconnectAttempts),
+	)
 
-	if a.mqttClient.IsConnected() {
-		topic := fmt.Sprintf("%s/state", a.config.MQTT.TopicPrefix)
-		payload := []byte(state)
-		if err := a.mqttClient.Publish(topic, payload, a.config.MQTT.QoS, a.config.MQTT.Retain, 3); err != nil {
-			a.logger.Error("Failed to publish state to MQTT after retries: %v", err)
-		}
-	} else {
-		a.logger.Warn("Cannot publish state change - MQTT not connected")
+	for _, printerConfig := range cfg.GetPrinters() {
+		printer := bridge.NewPrinter(&printerConfig, cfg, mqttClient, logger)
+		app.printers = append(app.printers, printer)
+		app.supervisor.Add(
+			supervisor.NewLink("moonraker/"+printer.Name(), printer.Connect, printer.IsConnected),
+			supervisor.NewPolicy(printerConfig.AutoReconnect, printerConfig.MaxReconnectAttempts),
+		)
 	}
-}
 
-func (a *App) OnNotification(method string, params any) {
-	a.logger.Debug("Received notification: %s", method)
-
-	if a.mqttClient.IsConnected() {
-		topic := fmt.Sprintf("%s/notifications/%s", a.config.MQTT.TopicPrefix, method)
+	if cfg.MQTT.ControlEnabled {
+		app.controller = bridge.NewController(app.printers, cfg, mqttClient, logger)
+	}
 
-		data, err := json.Marshal(params)
-		if err != nil {
-			a.logger.Error("Failed to marshal notification params: %v", err)
-			return
-		}
+	app.supervisor.OnEvent(app.handleLinkEvent)
 
-		if err := a.mqttClient.Publish(topic, data, a.config.MQTT.QoS, a.config.MQTT.Retain, 3); err != nil {
-			a.logger.Error("Failed to publish notification to MQTT after retries: %v", err)
-		}
-	} else {
-		a.logger.Warn("Cannot publish notification '%s' - MQTT not connected", method)
-	}
+	return app, nil
 }
 
-func (a *App) OnException(err error) {
-	a.logger.Error("Moonraker exception: %v", err)
-}
+func (a *App) Run(ctx context.Context, watch bool, reloadSignals <-chan os.Signal) error {
+	cfg := a.currentConfig()
 
-func (a *App) Run(ctx context.Context) error {
 	a.logger.Info("Starting Moonraker2MQTT")
 	a.logger.Info("Version: %s, Git Commit: %s, Build Date: %s", version.Version, version.GitCommit, version.BuildDate)
 
@@ -113,46 +115,44 @@ func (a *App) Run(ctx context.Context) error {
 		}
 	}()
 
-	if err := a.moonrakerClient.Connect(ctx); err != nil {
-		return fmt.Errorf("failed to connect to Moonraker: %w", err)
-	}
-	defer func() {
-		if err := a.moonrakerClient.Disconnect(); err != nil {
-			a.logger.Error("Failed to disconnect from Moonraker: %v", err)
+	for _, printer := range a.printers {
+		if err := printer.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start printer %s: %w", printer.Name(), err)
 		}
-	}()
+		defer printer.Stop()
+	}
+
+	a.logger.Info("Successfully connected %d printer(s) to MQTT", len(a.printers))
 
-	a.logger.Info("Successfully connected to both Moonraker and MQTT")
+	a.subscribeHomeAssistantStatus(&cfg.HomeAssistant)
 
-	if a.config.MQTT.CommandsEnabled {
-		commandTopic := fmt.Sprintf("%s/%s", a.config.MQTT.TopicPrefix, "commands")
-		if err := a.mqttClient.Subscribe(commandTopic, a.moonrakerClient.HandleCommand); err != nil {
-			a.logger.Warn("Failed to subscribe to command topic %s: %v", commandTopic, err)
-		} else {
-			a.logger.Info("Subscribed to command topic: %s", commandTopic)
+	if a.controller != nil {
+		if err := a.controller.Subscribe(); err != nil {
+			a.logger.Warn("Failed to subscribe to bridge control topic: %v", err)
+		}
+		if err := a.controller.PublishConfig(); err != nil {
+			a.logger.Warn("Failed to publish bridge config: %v", err)
 		}
 	}
 
-	maxRetries := 3
-	for retries := 0; retries < maxRetries; retries++ {
-		if err := a.publishInitialInfo(ctx); err != nil {
-			a.logger.Warn("Failed to publish initial info (attempt %d/%d): %v", retries+1, maxRetries, err)
-			if retries == maxRetries-1 {
-				a.logger.Error("Failed to publish initial info after %d attempts, continuing anyway", maxRetries)
-			} else {
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-time.After(time.Second * time.Duration(retries+1)):
-				}
-			}
-		} else {
-			a.logger.Info("Successfully published initial info")
-			break
+	if cfg.HTTP.Enabled {
+		if cfg.HTTP.Telemetry {
+			a.telemetry = bridge.NewTelemetry(a.printers, cfg.HTTP.GetTelemetryMetrics())
+			a.telemetry.Register(metrics.DefaultRegistry)
+		}
+
+		server := metrics.NewServer(&cfg.HTTP, metrics.DefaultRegistry, a.readiness, a.logger)
+		if err := server.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start HTTP server: %w", err)
 		}
 	}
 
+	go a.supervisor.Run(ctx)
 	go a.periodicMonitoring(ctx)
+	go a.handleReloadSignals(ctx, reloadSignals)
+	if watch {
+		go a.watchConfig(ctx)
+	}
 
 	<-ctx.Done()
 	a.logger.Info("Shutting down...")
@@ -160,182 +160,107 @@ func (a *App) Run(ctx context.Context) error {
 	return nil
 }
 
-func (a *App) publishInitialInfo(ctx context.Context) error {
-	serverInfo, err := a.moonrakerClient.GetServerInfo(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get server info: %w", err)
+func setAvailabilityMessages(mqttClient *mqtt.PahoClient, mqttConfig *config.MQTTConfig) {
+	if !mqttConfig.AvailabilityEnabled {
+		mqttClient.ClearStatusMessages()
+		return
 	}
 
-	data, err := json.Marshal(serverInfo)
-	if err != nil {
-		return fmt.Errorf("failed to marshal server info: %w", err)
-	}
+	bridgeTopic := bridge.BridgeAvailabilityTopic(mqttConfig.TopicPrefix)
+	mqttClient.SetWill(bridgeTopic, []byte(mqtt.AVAILABILITY_OFFLINE), mqttConfig.QoS, true)
+	mqttClient.SetBirth(bridgeTopic, []byte(mqtt.AVAILABILITY_ONLINE), mqttConfig.QoS, true)
+}
 
-	topic := fmt.Sprintf("%s/server/info", a.config.MQTT.TopicPrefix)
-	if err := a.mqttClient.Publish(topic, data, a.config.MQTT.QoS, a.config.MQTT.Retain, 3); err != nil {
-		return fmt.Errorf("failed to publish server info: %w", err)
+func (a *App) subscribeHomeAssistantStatus(homeAssistant *config.HomeAssistantConfig) {
+	if !homeAssistant.Enabled {
+		return
 	}
 
-	printerInfo, err := a.moonrakerClient.GetHostInfo(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get printer info: %w", err)
+	statusTopic := homeAssistantStatusTopic(homeAssistant)
+	if err := a.mqttClient.Subscribe(statusTopic, a.handleHomeAssistantStatus); err != nil {
+		a.logger.Warn("Failed to subscribe to Home Assistant status topic %s: %v", statusTopic, err)
 	}
+}
 
-	data, err = json.Marshal(printerInfo)
-	if err != nil {
-		return fmt.Errorf("failed to marshal printer info: %w", err)
-	}
+func homeAssistantStatusTopic(homeAssistant *config.HomeAssistantConfig) string {
+	return fmt.Sprintf("%s/status", homeAssistant.GetDiscoveryPrefix())
+}
 
-	topic = fmt.Sprintf("%s/printer/info", a.config.MQTT.TopicPrefix)
-	if err := a.mqttClient.Publish(topic, data, a.config.MQTT.QoS, a.config.MQTT.Retain, 3); err != nil {
-		return fmt.Errorf("failed to publish printer info: %w", err)
+func (a *App) handleHomeAssistantStatus(topic string, payload []byte) {
+	if string(payload) != homeassistant.STATUS_ONLINE {
+		return
 	}
 
-	return nil
+	a.logger.Info("Home Assistant came online, re-announcing discovery")
+
+	for _, printer := range a.printers {
+		go func(printer *bridge.Printer) {
+			ctx, cancel := context.WithTimeout(context.Background(), printer.Timeout())
+			defer cancel()
+
+			if err := printer.PublishDiscovery(ctx); err != nil {
+				a.logger.With("printer", printer.Name()).Warn("Failed to re-announce Home Assistant discovery: %v", err)
+			}
+		}(printer)
+	}
 }
 
 func (a *App) periodicMonitoring(ctx context.Context) {
-	ticker := time.NewTicker(time.Duration(a.config.Moonraker.CallInterval) * time.Second)
+	ticker := time.NewTicker(bridge.HEALTH_PUBLISH_INTERVAL)
 	defer ticker.Stop()
 
-	consecutiveErrors := 0
-	maxConsecutiveErrors := 5
-	lastReconnectAttempt := time.Time{}
-	reconnectCooldown := 30 * time.Second
-
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			mqttConnected := a.mqttClient.IsConnected()
-			moonrakerConnected := a.moonrakerClient.IsConnected()
-
-			if !moonrakerConnected && time.Since(lastReconnectAttempt) > reconnectCooldown {
-				a.logger.Warn("Moonraker disconnected, attempting reconnection...")
-				lastReconnectAttempt = time.Now()
-
-				if err := a.moonrakerClient.Connect(ctx); err != nil {
-					a.logger.Error("Failed to reconnect Moonraker: %v", err)
-					consecutiveErrors++
-					continue
-				} else {
-					a.logger.Info("Moonraker reconnected successfully")
-				}
-			}
-
-			if !mqttConnected && time.Since(lastReconnectAttempt) > reconnectCooldown {
-				a.logger.Warn("MQTT disconnected, attempting reconnection...")
-				lastReconnectAttempt = time.Now()
-
-				if err := a.mqttClient.Connect(); err != nil {
-					a.logger.Error("Failed to reconnect MQTT: %v", err)
-					consecutiveErrors++
-					continue
-				} else {
-					a.logger.Info("MQTT reconnected successfully")
-					if a.config.MQTT.CommandsEnabled {
-						commandTopic := fmt.Sprintf("%s/%s", a.config.MQTT.TopicPrefix, "commands")
-						if err := a.mqttClient.Subscribe(commandTopic, a.moonrakerClient.HandleCommand); err != nil {
-							a.logger.Warn("Failed to re-subscribe to command topic %s after reconnection: %v", commandTopic, err)
-						} else {
-							a.logger.Info("Re-subscribed to command topic: %s", commandTopic)
-						}
-					}
-				}
-			}
-
-			if !mqttConnected || !moonrakerConnected {
-				consecutiveErrors++
-				if consecutiveErrors <= 5 {
-					a.logger.Warn("Skipping status publication - MQTT=%t, Moonraker=%t", mqttConnected, moonrakerConnected)
-				}
-				continue
-			}
-
-			if err := a.publishStatus(ctx); err != nil {
-				consecutiveErrors++
-				a.logger.Error("Failed to publish periodic status (error %d/%d): %v", consecutiveErrors, maxConsecutiveErrors, err)
-
-				if consecutiveErrors >= maxConsecutiveErrors {
-					a.logger.Warn("Too many consecutive errors, slowing down polling interval")
-					ticker.Stop()
-					ticker = time.NewTicker(time.Duration(a.config.Moonraker.CallInterval*2) * time.Second)
-				}
-			} else {
-				if consecutiveErrors > 0 {
-					a.logger.Info("Successfully published status after %d errors, resuming normal polling", consecutiveErrors)
-					consecutiveErrors = 0
-					ticker.Stop()
-					ticker = time.NewTicker(time.Duration(a.config.Moonraker.CallInterval) * time.Second)
-				}
-			}
+			a.publishHealth()
 		}
 	}
 }
 
-func (a *App) publishStatus(ctx context.Context) error {
-	klippyState, err := a.moonrakerClient.GetKlippyState(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get klipper state: %w", err)
-	}
-
-	topic := fmt.Sprintf("%s/klipper/state", a.config.MQTT.TopicPrefix)
-	if err := a.mqttClient.Publish(topic, []byte(klippyState), a.config.MQTT.QoS, false, 3); err != nil {
-		return fmt.Errorf("failed to publish klipper state: %w", err)
-	}
+func (a *App) handleLinkEvent(event supervisor.Event) {
+	a.publishHealth()
+}
 
-	objects, err := a.config.Moonraker.GetMonitoredObjects()
-	if err != nil {
-		a.logger.Warn("Failed to get monitored objects from config, using defaults: %v", err)
-		objects = map[string]any{
-			"print_stats": nil,
-			"toolhead":    []string{"position"},
-			"extruder":    []string{"temperature", "target"},
-			"heater_bed":  []string{"temperature", "target"},
-		}
+func (a *App) publishHealth() {
+	if !a.mqttClient.IsConnected() {
+		return
 	}
 
-	result, err := a.moonrakerClient.QueryObjects(ctx, objects)
-	if err != nil {
-		return fmt.Errorf("failed to query objects: %w", err)
+	health := bridge.NewBridgeHealth(true, a.printers, time.Now())
+	health.Links = a.supervisor.States()
+	cfg := a.currentConfig()
+	if err := bridge.PublishBridgeHealth(a.mqttClient, cfg.MQTT.TopicPrefix, cfg.MQTT.QoS, health); err != nil {
+		a.logger.Warn("Failed to publish bridge health: %v", err)
 	}
+}
 
-	errorCount := 0
-	totalObjects := len(result)
-	for objectName, objectData := range result {
-		if objectName == "eventtime" {
-			continue
-		}
-
-		data, err := json.Marshal(objectData)
-		if err != nil {
-			a.logger.Error("Failed to marshal object %s: %v", objectName, err)
-			errorCount++
-			continue
-		}
-
-		topic := fmt.Sprintf("%s/objects/%s", a.config.MQTT.TopicPrefix, objectName)
-		if err := a.mqttClient.Publish(topic, data, a.config.MQTT.QoS, false, 3); err != nil {
-			a.logger.Error("Failed to publish object %s after retries: %v", objectName, err)
-			errorCount++
-		}
+func (a *App) readiness() (bool, map[string]string) {
+	ready := a.mqttClient.IsConnected()
+	checks := map[string]string{"mqtt": supervisor.LINK_STATE_DISCONNECTED}
+	if ready {
+		checks["mqtt"] = supervisor.LINK_STATE_CONNECTED
 	}
 
-	if errorCount > 0 {
-		a.logger.Warn("Published objects with %d/%d errors", errorCount, totalObjects)
-		if errorCount >= totalObjects/2 {
-			return fmt.Errorf("too many object publication failures (%d/%d)", errorCount, totalObjects)
+	for _, health := range bridge.NewBridgeHealth(ready, a.printers, time.Now()).Printers {
+		klippyState := health.KlippyState
+		if klippyState == "" {
+			klippyState = "unknown"
 		}
+		checks["moonraker/"+health.Name] = health.State
+		checks["klippy/"+health.Name] = klippyState
+		ready = ready && health.Ready()
 	}
 
-	return nil
+	return ready, checks
 }
 
 func main() {
 	configFile := flag.String("config", DEFAULT_CONFIG_FILE, "Configuration file path")
 	generateConfig := flag.Bool("generate-config", false, "Generate a default configuration file and exit")
 	showVersion := flag.Bool("version", false, "Show version information and exit")
+	watchConfig := flag.Bool("watch-config", false, "Reload the configuration file when it changes")
 	flag.Parse()
 
 	if *showVersion {
@@ -388,9 +313,16 @@ func main() {
 		}
 	}()
 
-	if err := app.Run(ctx); err != nil {
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+	if err := app.Run(ctx, *watchConfig, reloadChan); err != nil {
 		log.Fatalf("Application error: %v", err)
 	}
 
+	if err := logger.Close(app.logger); err != nil {
+		log.Printf("Failed to close log outputs: %v", err)
+	}
+
 	log.Println("Application shutdown complete")
 }
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"moonraker2mqtt/config"
+	"moonraker2mqtt/mqtt/mqtttest"
 )
 
 func TestNewApp_ValidConfig(t *testing.T) {
@@ -221,3 +224,197 @@ func TestDefaultConfigGeneration(t *testing.T) {
 		t.Errorf("Expected default MQTT port 1883, got %d", app.config.MQTT.Port)
 	}
 }
+
+func TestApp_Reload(t *testing.T) {
+	baseConfig := `environment: testing
+moonraker:
+  host: localhost
+  port: 7125
+  timeout: 30
+  call_interval: 2
+mqtt:
+  host: localhost
+  port: 1883
+  client_id: test-client
+  topic_prefix: test
+  qos: 0
+logging:
+  level: info
+  format: text
+  outputs:
+    - type: stderr`
+
+	tests := []struct {
+		name         string
+		config       string
+		wantErr      bool
+		wantLevel    string
+		wantQoS      byte
+		wantInterval int
+	}{
+		{
+			name:         "log level and call interval are applied",
+			config:       strings.Replace(strings.Replace(baseConfig, "level: info", "level: debug", 1), "call_interval: 2", "call_interval: 5", 1),
+			wantLevel:    "debug",
+			wantInterval: 5,
+		},
+		{
+			name:         "mqtt settings are applied",
+			config:       strings.Replace(baseConfig, "qos: 0", "qos: 1", 1),
+			wantLevel:    "info",
+			wantQoS:      1,
+			wantInterval: 2,
+		},
+		{
+			name:         "restart required settings keep their running value",
+			config:       strings.Replace(baseConfig, "format: text", "format: json", 1),
+			wantLevel:    "info",
+			wantInterval: 2,
+		},
+		{
+			name:         "invalid MQTT TLS config is rejected",
+			config:       strings.Replace(strings.Replace(baseConfig, "level: info", "level: debug", 1), "qos: 0", "qos: 0\n  use_tls: true\n  tls:\n    ca_file: /nonexistent/ca.pem", 1),
+			wantErr:      true,
+			wantLevel:    "info",
+			wantInterval: 2,
+		},
+		{
+			name:         "invalid config is rejected",
+			config:       strings.Replace(baseConfig, "call_interval: 2", "call_interval: 0", 1),
+			wantErr:      true,
+			wantLevel:    "info",
+			wantInterval: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := t.TempDir() + "/config.yaml"
+			if err := os.WriteFile(configPath, []byte(baseConfig), 0644); err != nil {
+				t.Fatalf("Failed to create test config: %v", err)
+			}
+
+			app, err := NewApp(configPath)
+			if err != nil {
+				t.Fatalf("NewApp() failed: %v", err)
+			}
+
+			if err := os.WriteFile(configPath, []byte(tt.config), 0644); err != nil {
+				t.Fatalf("Failed to update test config: %v", err)
+			}
+
+			err = app.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, wantErr %t", err, tt.wantErr)
+			}
+
+			cfg := app.currentConfig()
+			if cfg.Logging.Level != tt.wantLevel {
+				t.Errorf("logging level = %s, want %s", cfg.Logging.Level, tt.wantLevel)
+			}
+			if cfg.MQTT.QoS != tt.wantQoS {
+				t.Errorf("mqtt qos = %d, want %d", cfg.MQTT.QoS, tt.wantQoS)
+			}
+			if interval := app.printers[0].CallInterval(); interval != time.Duration(tt.wantInterval)*time.Second {
+				t.Errorf("call interval = %s, want %ds", interval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestApp_ReloadAppliesLive(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+
+	baseConfig := fmt.Sprintf(`environment: testing
+moonraker:
+  host: localhost
+  port: 7125
+  timeout: 30
+  call_interval: 2
+  monitored_objects: '{"print_stats": null}'
+mqtt:
+  host: 127.0.0.1
+  port: %d
+  client_id: test-client
+  topic_prefix: test
+  qos: 0
+  commands_enabled: true
+  availability_enabled: true
+publish:
+  only_on_change: false
+logging:
+  level: info
+  format: text
+  outputs:
+    - type: stderr`, broker.Port())
+
+	configPath := t.TempDir() + "/config.yaml"
+	if err := os.WriteFile(configPath, []byte(baseConfig), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	app, err := NewApp(configPath)
+	if err != nil {
+		t.Fatalf("NewApp() failed: %v", err)
+	}
+	if err := app.mqttClient.Connect(); err != nil {
+		t.Fatalf("Connect() failed: %v", err)
+	}
+	defer app.mqttClient.Disconnect()
+
+	updated := strings.NewReplacer(
+		"call_interval: 2", "call_interval: 5",
+		`'{"print_stats": null}'`, `'{"print_stats": null, "extruder": ["temperature"]}'`,
+		"topic_prefix: test", "topic_prefix: farm",
+		"only_on_change: false", "only_on_change: true",
+	).Replace(baseConfig)
+	if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		t.Fatalf("Failed to update test config: %v", err)
+	}
+
+	if err := app.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	printer := app.printers[0]
+	if interval := printer.CallInterval(); interval != 5*time.Second {
+		t.Errorf("call interval = %s, want 5s", interval)
+	}
+	if objects := printer.MonitoredObjects(); len(objects) != 2 {
+		t.Errorf("monitored objects = %v, want print_stats and extruder", objects)
+	}
+	if topic := printer.TopicPrefix(); topic != "farm" {
+		t.Errorf("topic prefix = %s, want farm", topic)
+	}
+	if cfg := app.currentConfig(); !cfg.Publish.OnlyOnChange {
+		t.Error("publish.only_on_change was not applied")
+	}
+
+	if app.mqttClient.IsConnected() {
+		t.Fatal("MQTT client did not disconnect to apply the new topic prefix")
+	}
+	if err := app.mqttClient.Connect(); err != nil {
+		t.Fatalf("Connect() after reload failed: %v", err)
+	}
+	app.publishHealth()
+
+	if !eventually(func() bool { return broker.Subscribed("farm/commands") }) {
+		t.Error("not subscribed to farm/commands after reload")
+	}
+	for _, topic := range []string{"farm/bridge/availability", "farm/bridge/health"} {
+		if !eventually(func() bool { return broker.Published(topic) }) {
+			t.Errorf("nothing published on %s after reload", topic)
+		}
+	}
+}
+
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
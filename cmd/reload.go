@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"moonraker2mqtt/bridge"
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/metrics"
+	"moonraker2mqtt/supervisor"
+)
+
+func (a *App) currentConfig() *config.Config {
+	a.configMux.RLock()
+	defer a.configMux.RUnlock()
+	return a.config
+}
+
+func (a *App) Reload() error {
+	a.reloadMux.Lock()
+	defer a.reloadMux.Unlock()
+
+	next, err := config.LoadConfig(a.configFile)
+	if err != nil {
+		return err
+	}
+
+	if err := next.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	current := a.currentConfig()
+	diff := current.Diff(next)
+	if diff.IsEmpty() {
+		a.logger.Info("Configuration unchanged")
+		return nil
+	}
+
+	keepRunningSettings(current, next, diff)
+
+	var tlsConfig *tls.Config
+	if diff.MQTTConnection || diff.TopicPrefix {
+		tlsConfig, err = next.MQTT.GetTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to build MQTT TLS config: %w", err)
+		}
+	}
+
+	a.applyConfig(current, next, diff, tlsConfig)
+
+	a.configMux.Lock()
+	a.config = next
+	a.configMux.Unlock()
+
+	if len(diff.RestartRequired) > 0 {
+		a.logger.Warn("Configuration partially reloaded from %s, restart required to apply %s", a.configFile, strings.Join(diff.RestartRequired, ", "))
+	} else {
+		a.logger.Info("Configuration reloaded from %s", a.configFile)
+	}
+
+	if a.controller != nil {
+		if err := a.controller.PublishConfig(); err != nil {
+			a.logger.Warn("Failed to publish bridge config: %v", err)
+		}
+	}
+	a.publishHealth()
+
+	return nil
+}
+
+func (a *App) applyConfig(current, next *config.Config, diff config.ConfigDiff, tlsConfig *tls.Config) {
+	if diff.Logging {
+		logger.Reconfigure(a.logger, &next.Logging)
+		a.logger.Info("Log level set to %s", strings.ToLower(next.Logging.Level))
+	}
+
+	printerConfigs := next.GetPrinters()
+	for _, printerDiff := range diff.Printers {
+		printer, printerConfig := a.printer(printerDiff.Name), a.printerConfig(printerConfigs, printerDiff.Name)
+		if printer == nil || printerConfig == nil {
+			continue
+		}
+
+		if err := printer.Reload(printerConfig, next, printerDiff); err != nil {
+			a.logger.With("printer", printer.Name()).Error("Failed to reload printer: %v", err)
+			continue
+		}
+
+		if printerDiff.Connection || printerDiff.Policy {
+			a.supervisor.Reset("moonraker/"+printer.Name(), supervisor.NewPolicy(printerConfig.AutoReconnect, printerConfig.MaxReconnectAttempts))
+		}
+	}
+
+	if a.controller != nil {
+		a.controller.Reload(next)
+	}
+
+	if current.HomeAssistant.Enabled != next.HomeAssistant.Enabled ||
+		current.HomeAssistant.GetDiscoveryPrefix() != next.HomeAssistant.GetDiscoveryPrefix() {
+		if current.HomeAssistant.Enabled {
+			if err := a.mqttClient.Unsubscribe(homeAssistantStatusTopic(&current.HomeAssistant)); err != nil {
+				a.logger.Warn("Failed to unsubscribe from Home Assistant status topic: %v", err)
+			}
+		}
+		a.subscribeHomeAssistantStatus(&next.HomeAssistant)
+	}
+
+	if diff.Telemetry && a.telemetry != nil {
+		a.telemetry.Unregister(metrics.DefaultRegistry)
+		a.telemetry = bridge.NewTelemetry(a.printers, next.HTTP.GetTelemetryMetrics())
+		a.telemetry.Register(metrics.DefaultRegistry)
+		a.logger.Info("Telemetry metrics updated")
+	}
+
+	if diff.MQTTConnection || diff.TopicPrefix {
+		a.logger.Info("MQTT settings changed, reconnecting to the broker")
+		if err := a.mqttClient.Disconnect(); err != nil {
+			a.logger.Warn("Failed to disconnect from MQTT broker: %v", err)
+		}
+
+		a.mqttClient.SetConnection(next.MQTT.Host, next.MQTT.Port, next.MQTT.ClientID, next.MQTT.Username, next.MQTT.Password, next.MQTT.UseTLS)
+		a.mqttClient.SetTLSConfig(tlsConfig)
+		setAvailabilityMessages(a.mqttClient, &next.MQTT)
+	}
+
+	if diff.MQTTConnection || diff.TopicPrefix || diff.MQTTPolicy {
+		a.supervisor.Reset("mqtt", supervisor.NewPolicy(next.MQTT.AutoReconnect, next.MQTT.MaxReconnectAttempts))
+	}
+}
+
+func keepRunningSettings(current, next *config.Config, diff config.ConfigDiff) {
+	next.Logging.Format = current.Logging.Format
+	next.Logging.Outputs = current.Logging.Outputs
+
+	next.MQTT.ControlEnabled = current.MQTT.ControlEnabled
+
+	if slices.Contains(diff.RestartRequired, "printers") {
+		next.Moonraker = current.Moonraker
+		next.Printers = current.Printers
+	}
+
+	next.HTTP.Enabled = current.HTTP.Enabled
+	next.HTTP.Host = current.HTTP.Host
+	next.HTTP.Port = current.HTTP.Port
+	next.HTTP.Telemetry = current.HTTP.Telemetry
+}
+
+func (a *App) printer(name string) *bridge.Printer {
+	for _, printer := range a.printers {
+		if printer.Name() == name {
+			return printer
+		}
+	}
+	return nil
+}
+
+func (a *App) printerConfig(printerConfigs []config.PrinterConfig, name string) *config.PrinterConfig {
+	for i := range printerConfigs {
+		if printerConfigs[i].Name == name {
+			return &printerConfigs[i]
+		}
+	}
+	return nil
+}
+
+func (a *App) watchConfig(ctx context.Context) {
+	lastModified := configModTime(a.configFile)
+	ticker := time.NewTicker(CONFIG_WATCH_INTERVAL)
+	defer ticker.Stop()
+
+	a.logger.Info("Watching %s for changes", a.configFile)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modified := configModTime(a.configFile)
+			if modified.IsZero() || modified.Equal(lastModified) {
+				continue
+			}
+			lastModified = modified
+
+			a.logger.Info("Configuration file %s changed, reloading", a.configFile)
+			if err := a.Reload(); err != nil {
+				a.logger.Error("Configuration reload failed, keeping the running configuration: %v", err)
+			}
+		}
+	}
+}
+
+func (a *App) handleReloadSignals(ctx context.Context, reloadSignals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reloadSignals:
+			a.logger.Info("Received SIGHUP, reloading configuration")
+			if err := a.Reload(); err != nil {
+				a.logger.Error("Configuration reload failed, keeping the running configuration: %v", err)
+			}
+		}
+	}
+}
+
+func configModTime(filename string) time.Time {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"os"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +22,72 @@ import (
 
 const DEFAULT_REQUEST_TIMEOUT = 30
 const DEFAULT_MAX_RECONNECT_ATTEMPTS = 10
+const DEFAULT_DISCOVERY_PREFIX = "homeassistant"
+const DEFAULT_PRINTER_NAME = "default"
+const DEFAULT_CLIENT_NAME = "moonraker2mqtt"
+const DEFAULT_CLIENT_TYPE = "bridge"
+const DEFAULT_HEARTBEAT_INTERVAL = 60
+const DEFAULT_KEEPALIVE_INTERVAL = 10
+const DEFAULT_LIVENESS_TIMEOUT = 30
+const DEFAULT_HTTP_HOST = "0.0.0.0"
+const DEFAULT_HTTP_PORT = 9464
+
+const (
+	FLATTEN_MODE_NONE  = "none"
+	FLATTEN_MODE_INDEX = "index"
+	FLATTEN_MODE_AXES  = "axes"
+	FLATTEN_ALL        = "*"
+
+	LOG_OUTPUT_STDOUT   = "stdout"
+	LOG_OUTPUT_STDERR   = "stderr"
+	LOG_OUTPUT_FILE     = "file"
+	LOG_OUTPUT_SYSLOG   = "syslog"
+	LOG_OUTPUT_JOURNALD = "journald"
+
+	PROGRESS_METHOD_FILE     = "file"
+	PROGRESS_METHOD_SLICER   = "slicer"
+	PROGRESS_METHOD_FILAMENT = "filament"
+)
+
+var DefaultTelemetryMetrics = map[string]string{
+	"extruder*.temperature":            "klipper_temperature_celsius",
+	"extruder*.target":                 "klipper_target_temperature_celsius",
+	"extruder*.power":                  "klipper_heater_power",
+	"heater_bed.temperature":           "klipper_temperature_celsius",
+	"heater_bed.target":                "klipper_target_temperature_celsius",
+	"heater_bed.power":                 "klipper_heater_power",
+	"heater_generic *.temperature":     "klipper_temperature_celsius",
+	"heater_generic *.target":          "klipper_target_temperature_celsius",
+	"heater_generic *.power":           "klipper_heater_power",
+	"temperature_sensor *.temperature": "klipper_temperature_celsius",
+	"temperature_fan *.temperature":    "klipper_temperature_celsius",
+	"temperature_fan *.target":         "klipper_target_temperature_celsius",
+	"temperature_fan *.speed":          "klipper_fan_speed",
+	"fan.speed":                        "klipper_fan_speed",
+	"fan_generic *.speed":              "klipper_fan_speed",
+	"heater_fan *.speed":               "klipper_fan_speed",
+	"controller_fan *.speed":           "klipper_fan_speed",
+	"toolhead.position":                "klipper_toolhead_position_mm",
+	"display_status.progress":          "klipper_print_progress",
+	"virtual_sdcard.progress":          "klipper_print_progress",
+	"print_stats.filament_used":        "klipper_filament_used_mm",
+	"print_stats.print_duration":       "klipper_print_duration_seconds",
+	"mcu*.last_stats.mcu_awake":        "klipper_mcu_awake",
+	"mcu*.last_stats.mcu_task_avg":     "klipper_mcu_task_avg_seconds",
+	"mcu*.last_stats.mcu_task_stddev":  "klipper_mcu_task_stddev_seconds",
+	"system_stats.sysload":             "klipper_system_load",
+	"system_stats.cputime":             "klipper_system_cpu_seconds",
+	"system_stats.memavail":            "klipper_system_memory_available_kb",
+}
+
+var LogComponents = []string{"app", "bridge", "http", "moonraker", "mqtt", "supervisor", "websocket"}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
 
 func LoadConfig(filename string) (*Config, error) {
 	file, err := os.Open(filename)
@@ -41,9 +112,34 @@ func LoadConfig(filename string) (*Config, error) {
 
 	overrideWithEnv(&config)
 
+	if err := resolvePrinters(data, &config); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
+func resolvePrinters(data []byte, config *Config) error {
+	var raw struct {
+		Printers []yaml.Node `yaml:"printers"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse printers: %w", err)
+	}
+
+	printers := make([]PrinterConfig, 0, len(raw.Printers))
+	for i := range raw.Printers {
+		printer := PrinterConfig{MoonrakerConfig: config.Moonraker}
+		if err := raw.Printers[i].Decode(&printer); err != nil {
+			return fmt.Errorf("failed to parse printer %d: %w", i+1, err)
+		}
+		printers = append(printers, printer)
+	}
+
+	config.Printers = printers
+	return nil
+}
+
 func overrideWithEnv(config *Config) {
 	if err := godotenv.Load(".env"); err != nil {
 		log.Printf("No .env file found or error loading it: %v", err)
@@ -95,6 +191,47 @@ func overrideWithEnv(config *Config) {
 		config.Moonraker.MonitoredObjects = monitoredObjects
 	}
 
+	if pollingFallback := os.Getenv("MOONRAKER_POLLING_FALLBACK"); pollingFallback != "" {
+		if pf, err := strconv.ParseBool(pollingFallback); err == nil {
+			config.Moonraker.PollingFallback = pf
+		}
+	}
+
+	if keepaliveInterval := os.Getenv("MOONRAKER_KEEPALIVE_INTERVAL"); keepaliveInterval != "" {
+		if ki, err := strconv.Atoi(keepaliveInterval); err == nil {
+			config.Moonraker.KeepaliveInterval = ki
+		}
+	}
+
+	if livenessTimeout := os.Getenv("MOONRAKER_LIVENESS_TIMEOUT"); livenessTimeout != "" {
+		if lt, err := strconv.Atoi(livenessTimeout); err == nil {
+			config.Moonraker.LivenessTimeout = lt
+		}
+	}
+
+	if username := os.Getenv("MOONRAKER_USERNAME"); username != "" {
+		config.Moonraker.Username = username
+	}
+	if password := os.Getenv("MOONRAKER_PASSWORD"); password != "" {
+		config.Moonraker.Password = password
+	}
+	if authSource := os.Getenv("MOONRAKER_AUTH_SOURCE"); authSource != "" {
+		config.Moonraker.AuthSource = authSource
+	}
+	if oneshotToken := os.Getenv("MOONRAKER_ONESHOT_TOKEN"); oneshotToken != "" {
+		if ot, err := strconv.ParseBool(oneshotToken); err == nil {
+			config.Moonraker.OneshotToken = ot
+		}
+	}
+	if clientName := os.Getenv("MOONRAKER_CLIENT_NAME"); clientName != "" {
+		config.Moonraker.ClientName = clientName
+	}
+	if clientType := os.Getenv("MOONRAKER_CLIENT_TYPE"); clientType != "" {
+		config.Moonraker.ClientType = clientType
+	}
+
+	overrideTLSWithEnv("MOONRAKER_", &config.Moonraker.TLS)
+
 	if host := os.Getenv("MQTT_HOST"); host != "" {
 		config.MQTT.Host = host
 	}
@@ -142,12 +279,194 @@ func overrideWithEnv(config *Config) {
 		}
 	}
 
+	if estopEnabled := os.Getenv("MQTT_ESTOP_ENABLED"); estopEnabled != "" {
+		if ee, err := strconv.ParseBool(estopEnabled); err == nil {
+			config.MQTT.EstopEnabled = ee
+		}
+	}
+
+	if controlEnabled := os.Getenv("MQTT_CONTROL_ENABLED"); controlEnabled != "" {
+		if ce, err := strconv.ParseBool(controlEnabled); err == nil {
+			config.MQTT.ControlEnabled = ce
+		}
+	}
+
+	if availabilityEnabled := os.Getenv("MQTT_AVAILABILITY_ENABLED"); availabilityEnabled != "" {
+		if ae, err := strconv.ParseBool(availabilityEnabled); err == nil {
+			config.MQTT.AvailabilityEnabled = ae
+		}
+	}
+
+	if rpcAllow := os.Getenv("MQTT_RPC_ALLOW"); rpcAllow != "" {
+		config.MQTT.RPCAllow = splitList(rpcAllow)
+	}
+	if rpcDeny := os.Getenv("MQTT_RPC_DENY"); rpcDeny != "" {
+		config.MQTT.RPCDeny = splitList(rpcDeny)
+	}
+
+	overrideTLSWithEnv("MQTT_", &config.MQTT.TLS)
+
+	if onlyOnChange := os.Getenv("PUBLISH_ONLY_ON_CHANGE"); onlyOnChange != "" {
+		if oc, err := strconv.ParseBool(onlyOnChange); err == nil {
+			config.Publish.OnlyOnChange = oc
+		}
+	}
+	if minInterval := os.Getenv("PUBLISH_MIN_INTERVAL"); minInterval != "" {
+		if mi, err := strconv.Atoi(minInterval); err == nil {
+			config.Publish.MinInterval = mi
+		}
+	}
+	if heartbeatInterval := os.Getenv("PUBLISH_HEARTBEAT_INTERVAL"); heartbeatInterval != "" {
+		if hi, err := strconv.Atoi(heartbeatInterval); err == nil {
+			config.Publish.HeartbeatInterval = hi
+		}
+	}
+	if deadbands := os.Getenv("PUBLISH_DEADBANDS"); deadbands != "" {
+		if db, err := parseDeadbands(deadbands); err == nil {
+			config.Publish.Deadbands = db
+		} else {
+			log.Printf("Ignoring PUBLISH_DEADBANDS: %v", err)
+		}
+	}
+	if flattenObjects := os.Getenv("PUBLISH_FLATTEN_OBJECTS"); flattenObjects != "" {
+		if fo, err := parseKeyValues(flattenObjects); err == nil {
+			config.Publish.FlattenObjects = fo
+		} else {
+			log.Printf("Ignoring PUBLISH_FLATTEN_OBJECTS: %v", err)
+		}
+	}
+	if progressMethod := os.Getenv("PUBLISH_PROGRESS_METHOD"); progressMethod != "" {
+		config.Publish.ProgressMethod = progressMethod
+	}
+
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
 		config.Logging.Level = level
 	}
 	if format := os.Getenv("LOG_FORMAT"); format != "" {
 		config.Logging.Format = format
 	}
+	if outputs := os.Getenv("LOG_OUTPUTS"); outputs != "" {
+		config.Logging.Outputs = parseLogOutputs(outputs)
+	}
+	if components := os.Getenv("LOG_COMPONENTS"); components != "" {
+		if levels, err := parseKeyValues(components); err == nil {
+			config.Logging.Components = levels
+		} else {
+			log.Printf("Ignoring LOG_COMPONENTS: %v", err)
+		}
+	}
+
+	if enabled := os.Getenv("HOMEASSISTANT_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			config.HomeAssistant.Enabled = e
+		}
+	}
+	if discoveryPrefix := os.Getenv("HOMEASSISTANT_DISCOVERY_PREFIX"); discoveryPrefix != "" {
+		config.HomeAssistant.DiscoveryPrefix = discoveryPrefix
+	}
+	if nodeID := os.Getenv("HOMEASSISTANT_NODE_ID"); nodeID != "" {
+		config.HomeAssistant.NodeID = nodeID
+	}
+
+	if enabled := os.Getenv("HTTP_ENABLED"); enabled != "" {
+		if e, err := strconv.ParseBool(enabled); err == nil {
+			config.HTTP.Enabled = e
+		}
+	}
+	if host := os.Getenv("HTTP_HOST"); host != "" {
+		config.HTTP.Host = host
+	}
+	if port := os.Getenv("HTTP_PORT"); port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			config.HTTP.Port = p
+		}
+	}
+	if telemetry := os.Getenv("HTTP_TELEMETRY"); telemetry != "" {
+		if t, err := strconv.ParseBool(telemetry); err == nil {
+			config.HTTP.Telemetry = t
+		}
+	}
+	if telemetryMetrics := os.Getenv("HTTP_TELEMETRY_METRICS"); telemetryMetrics != "" {
+		if metrics, err := parseKeyValues(telemetryMetrics); err == nil {
+			config.HTTP.TelemetryMetrics = metrics
+		} else {
+			log.Printf("Ignoring HTTP_TELEMETRY_METRICS: %v", err)
+		}
+	}
+}
+
+func overrideTLSWithEnv(prefix string, config *TLSConfig) {
+	if caFile := os.Getenv(prefix + "TLS_CA_FILE"); caFile != "" {
+		config.CAFile = caFile
+	}
+	if certFile := os.Getenv(prefix + "TLS_CERT_FILE"); certFile != "" {
+		config.CertFile = certFile
+	}
+	if keyFile := os.Getenv(prefix + "TLS_KEY_FILE"); keyFile != "" {
+		config.KeyFile = keyFile
+	}
+	if serverName := os.Getenv(prefix + "TLS_SERVER_NAME"); serverName != "" {
+		config.ServerName = serverName
+	}
+	if minVersion := os.Getenv(prefix + "TLS_MIN_VERSION"); minVersion != "" {
+		config.MinVersion = minVersion
+	}
+	if insecure := os.Getenv(prefix + "TLS_INSECURE_SKIP_VERIFY"); insecure != "" {
+		if is, err := strconv.ParseBool(insecure); err == nil {
+			config.InsecureSkipVerify = is
+		}
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseKeyValues(value string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, item := range splitList(value) {
+		key, rawValue, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("'%s' must be in the form key=value", item)
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(rawValue)
+	}
+	return values, nil
+}
+
+func parseLogOutputs(value string) []LogOutputConfig {
+	var outputs []LogOutputConfig
+	for _, item := range splitList(value) {
+		outputType, path, _ := strings.Cut(item, ":")
+		outputs = append(outputs, LogOutputConfig{
+			Type: strings.TrimSpace(outputType),
+			Path: strings.TrimSpace(path),
+		})
+	}
+	return outputs
+}
+
+func parseDeadbands(value string) (map[string]float64, error) {
+	values, err := parseKeyValues(value)
+	if err != nil {
+		return nil, err
+	}
+
+	deadbands := make(map[string]float64, len(values))
+	for key, rawValue := range values {
+		deadband, err := strconv.ParseFloat(rawValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deadband value for '%s': %w", key, err)
+		}
+		deadbands[key] = deadband
+	}
+	return deadbands, nil
 }
 
 func SaveConfig(config *Config, filename string) error {
@@ -205,6 +524,32 @@ func GenerateDefaultConfig(filename string) error {
 	return SaveConfig(config, filename)
 }
 
+func (c *Config) GetPrinters() []PrinterConfig {
+	if len(c.Printers) == 0 {
+		return []PrinterConfig{{
+			Name:            DEFAULT_PRINTER_NAME,
+			Topic:           "",
+			MoonrakerConfig: c.Moonraker,
+		}}
+	}
+
+	printers := make([]PrinterConfig, len(c.Printers))
+	for i, printer := range c.Printers {
+		if printer.Topic == "" {
+			printer.Topic = printer.Name
+		}
+		printers[i] = printer
+	}
+	return printers
+}
+
+func (p *PrinterConfig) GetTopicPrefix(topicPrefix string) string {
+	if p.Topic == "" {
+		return topicPrefix
+	}
+	return fmt.Sprintf("%s/%s", topicPrefix, p.Topic)
+}
+
 func (m *MoonrakerConfig) GetWebSocketURL() string {
 	protocol := "ws"
 	if m.SSL {
@@ -213,6 +558,28 @@ func (m *MoonrakerConfig) GetWebSocketURL() string {
 	return fmt.Sprintf("%s://%s:%d/websocket", protocol, m.Host, m.Port)
 }
 
+func (m *MoonrakerConfig) GetHTTPURL() string {
+	protocol := "http"
+	if m.SSL {
+		protocol = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", protocol, m.Host, m.Port)
+}
+
+func (m *MoonrakerConfig) GetClientName() string {
+	if m.ClientName == "" {
+		return DEFAULT_CLIENT_NAME
+	}
+	return m.ClientName
+}
+
+func (m *MoonrakerConfig) GetClientType() string {
+	if m.ClientType == "" {
+		return DEFAULT_CLIENT_TYPE
+	}
+	return m.ClientType
+}
+
 func (m *MoonrakerConfig) GetTimeout() time.Duration {
 	if m.Timeout <= 0 {
 		return time.Duration(DEFAULT_REQUEST_TIMEOUT) * time.Second
@@ -220,8 +587,94 @@ func (m *MoonrakerConfig) GetTimeout() time.Duration {
 	return time.Duration(m.Timeout) * time.Second
 }
 
+func (m *MoonrakerConfig) GetKeepaliveInterval() time.Duration {
+	if m.KeepaliveInterval < 0 {
+		return 0
+	}
+	if m.KeepaliveInterval == 0 {
+		return time.Duration(DEFAULT_KEEPALIVE_INTERVAL) * time.Second
+	}
+	return time.Duration(m.KeepaliveInterval) * time.Second
+}
+
+func (m *MoonrakerConfig) GetLivenessTimeout() time.Duration {
+	if m.LivenessTimeout <= 0 {
+		return time.Duration(DEFAULT_LIVENESS_TIMEOUT) * time.Second
+	}
+	return time.Duration(m.LivenessTimeout) * time.Second
+}
+
 func (m *MQTTConfig) GetMQTTBrokerURL() string {
-	return fmt.Sprintf("tcp://%s:%d", m.Host, m.Port)
+	scheme := "tcp"
+	if m.UseTLS {
+		scheme = "tls"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, m.Host, m.Port)
+}
+
+func (m *MQTTConfig) GetTLSConfig() (*tls.Config, error) {
+	if !m.UseTLS {
+		return nil, nil
+	}
+	return m.TLS.Build()
+}
+
+func (m *MoonrakerConfig) GetTLSConfig() (*tls.Config, error) {
+	if !m.SSL {
+		return nil, nil
+	}
+	return m.TLS.Build()
+}
+
+func (t *TLSConfig) Build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("tls min version must be one of 1.0, 1.1, 1.2, 1.3, got '%s'", t.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if t.CAFile != "" {
+		data, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("tls ca file '%s' contains no valid PEM certificates", t.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, fmt.Errorf("tls cert file and key file must be set together")
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (t *TLSConfig) IsSet() bool {
+	return *t != TLSConfig{}
+}
+
+func (t *TLSConfig) Validate() error {
+	_, err := t.Build()
+	return err
 }
 
 func (m *MoonrakerConfig) GetMonitoredObjects() (map[string]any, error) {
@@ -244,6 +697,14 @@ func (m *MoonrakerConfig) GetMonitoredObjects() (map[string]any, error) {
 		return nil, fmt.Errorf("failed to parse monitored objects JSON: %w", err)
 	}
 
+	if err := ValidateMonitoredObjects(objects); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func ValidateMonitoredObjects(objects map[string]any) error {
 	for objectName, objectValue := range objects {
 		if objectValue == nil {
 			continue
@@ -253,15 +714,16 @@ func (m *MoonrakerConfig) GetMonitoredObjects() (map[string]any, error) {
 		case []interface{}:
 			for i, item := range v {
 				if _, ok := item.(string); !ok {
-					return nil, fmt.Errorf("monitored object '%s' field %d must be a string, got %T", objectName, i, item)
+					return fmt.Errorf("monitored object '%s' field %d must be a string, got %T", objectName, i, item)
 				}
 			}
+		case []string:
 		default:
-			return nil, fmt.Errorf("monitored object '%s' must be null or an array of strings, got %T", objectName, v)
+			return fmt.Errorf("monitored object '%s' must be null or an array of strings, got %T", objectName, v)
 		}
 	}
 
-	return objects, nil
+	return nil
 }
 
 func DefaultConfig() *Config {
@@ -277,6 +739,15 @@ func DefaultConfig() *Config {
 			MaxReconnectAttempts: DEFAULT_MAX_RECONNECT_ATTEMPTS,
 			CallInterval:         2,
 			MonitoredObjects:     `{"print_stats":null,"toolhead":["position"],"extruder":["temperature","target"],"heater_bed":["temperature","target"]}`,
+			PollingFallback:      false,
+			KeepaliveInterval:    DEFAULT_KEEPALIVE_INTERVAL,
+			LivenessTimeout:      DEFAULT_LIVENESS_TIMEOUT,
+			Username:             "",
+			Password:             "",
+			AuthSource:           "",
+			OneshotToken:         false,
+			ClientName:           DEFAULT_CLIENT_NAME,
+			ClientType:           DEFAULT_CLIENT_TYPE,
 		},
 		MQTT: MQTTConfig{
 			Host:                 "localhost",
@@ -291,27 +762,70 @@ func DefaultConfig() *Config {
 			AutoReconnect:        true,
 			MaxReconnectAttempts: DEFAULT_MAX_RECONNECT_ATTEMPTS,
 			CommandsEnabled:      true,
+			EstopEnabled:         false,
+			ControlEnabled:       false,
+			AvailabilityEnabled:  true,
+			RPCAllow:             []string{},
+			RPCDeny:              []string{"machine.reboot", "machine.shutdown"},
+		},
+		Publish: PublishConfig{
+			OnlyOnChange:      false,
+			MinInterval:       0,
+			HeartbeatInterval: DEFAULT_HEARTBEAT_INTERVAL,
+			Deadbands:         map[string]float64{},
+			FlattenObjects:    map[string]string{},
+			ProgressMethod:    PROGRESS_METHOD_FILE,
 		},
 		Logging: LoggingConfig{
-			Level:  "info",
-			Format: "text",
+			Level:      "info",
+			Format:     "text",
+			Outputs:    []LogOutputConfig{{Type: LOG_OUTPUT_STDOUT}},
+			Components: map[string]string{},
+		},
+		HomeAssistant: HomeAssistantConfig{
+			Enabled:         false,
+			DiscoveryPrefix: DEFAULT_DISCOVERY_PREFIX,
+			NodeID:          "",
+		},
+		HTTP: HTTPConfig{
+			Enabled:          false,
+			Host:             DEFAULT_HTTP_HOST,
+			Port:             DEFAULT_HTTP_PORT,
+			Telemetry:        true,
+			TelemetryMetrics: map[string]string{},
 		},
 	}
 }
 
 func (c *Config) Validate() error {
-	if err := c.Moonraker.Validate(); err != nil {
-		return fmt.Errorf("moonraker config validation failed: %w", err)
+	if len(c.Printers) == 0 {
+		if err := c.Moonraker.Validate(); err != nil {
+			return fmt.Errorf("moonraker config validation failed: %w", err)
+		}
+	} else if err := c.validatePrinters(); err != nil {
+		return err
 	}
 
 	if err := c.MQTT.Validate(); err != nil {
 		return fmt.Errorf("mqtt config validation failed: %w", err)
 	}
 
+	if err := c.Publish.Validate(); err != nil {
+		return fmt.Errorf("publish config validation failed: %w", err)
+	}
+
 	if err := c.Logging.Validate(); err != nil {
 		return fmt.Errorf("logging config validation failed: %w", err)
 	}
 
+	if err := c.HomeAssistant.Validate(); err != nil {
+		return fmt.Errorf("home assistant config validation failed: %w", err)
+	}
+
+	if err := c.HTTP.Validate(); err != nil {
+		return fmt.Errorf("http config validation failed: %w", err)
+	}
+
 	validEnvs := []string{"development", "production", "testing"}
 	found := false
 	for _, env := range validEnvs {
@@ -355,9 +869,79 @@ func (m *MoonrakerConfig) Validate() error {
 		}
 	}
 
+	if m.LivenessTimeout < 0 {
+		return fmt.Errorf("moonraker liveness timeout must be non-negative, got %d", m.LivenessTimeout)
+	}
+
+	if keepalive := m.GetKeepaliveInterval(); keepalive > 0 && m.GetLivenessTimeout() <= keepalive {
+		return fmt.Errorf("moonraker liveness timeout (%s) must be greater than the keepalive interval (%s)", m.GetLivenessTimeout(), keepalive)
+	}
+
+	if m.Password != "" && m.Username == "" {
+		return fmt.Errorf("moonraker password requires a username")
+	}
+
+	if m.Username != "" && m.Password == "" {
+		return fmt.Errorf("moonraker username requires a password")
+	}
+
+	if m.OneshotToken && m.Username == "" && m.APIKey == "" {
+		return fmt.Errorf("moonraker oneshot token requires an api key or a username and password")
+	}
+
+	if m.TLS.IsSet() && !m.SSL {
+		return fmt.Errorf("moonraker tls options require ssl to be enabled")
+	}
+
+	if err := m.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid moonraker tls config: %w", err)
+	}
+
 	return nil
 }
 
+func (c *Config) validatePrinters() error {
+	names := make(map[string]bool)
+	topics := make(map[string]bool)
+	for _, printer := range c.GetPrinters() {
+		if err := printer.Validate(); err != nil {
+			return fmt.Errorf("printer '%s' config validation failed: %w", printer.Name, err)
+		}
+
+		if names[printer.Name] {
+			return fmt.Errorf("duplicate printer name '%s'", printer.Name)
+		}
+		names[printer.Name] = true
+
+		if topics[printer.Topic] {
+			return fmt.Errorf("duplicate printer topic '%s'", printer.Topic)
+		}
+		topics[printer.Topic] = true
+	}
+	return nil
+}
+
+func (p *PrinterConfig) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("printer name cannot be empty")
+	}
+
+	topic := p.Topic
+	if topic == "" {
+		topic = p.Name
+	}
+
+	if strings.ContainsAny(topic, "/+#") || strings.TrimSpace(topic) != topic {
+		return fmt.Errorf("printer topic must be a single topic level without wildcards, got '%s'", topic)
+	}
+
+	if topic == "bridge" {
+		return fmt.Errorf("printer topic 'bridge' is reserved")
+	}
+
+	return p.MoonrakerConfig.Validate()
+}
+
 func (m *MQTTConfig) Validate() error {
 	if strings.TrimSpace(m.Host) == "" {
 		return fmt.Errorf("mqtt host cannot be empty")
@@ -387,6 +971,120 @@ func (m *MQTTConfig) Validate() error {
 		return fmt.Errorf("mqtt topic prefix should not start or end with '/', got '%s'", m.TopicPrefix)
 	}
 
+	for _, pattern := range append(append([]string{}, m.RPCAllow...), m.RPCDeny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("mqtt rpc method pattern '%s' is invalid: %w", pattern, err)
+		}
+	}
+
+	if m.TLS.IsSet() && !m.UseTLS {
+		return fmt.Errorf("mqtt tls options require use_tls to be enabled")
+	}
+
+	if err := m.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid mqtt tls config: %w", err)
+	}
+
+	return nil
+}
+
+func (m *MQTTConfig) IsRPCMethodAllowed(method string) bool {
+	for _, pattern := range m.RPCDeny {
+		if matched, _ := path.Match(pattern, method); matched {
+			return false
+		}
+	}
+
+	for _, pattern := range m.RPCAllow {
+		if matched, _ := path.Match(pattern, method); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *PublishConfig) GetMinInterval() time.Duration {
+	return time.Duration(p.MinInterval) * time.Second
+}
+
+func (p *PublishConfig) GetHeartbeatInterval() time.Duration {
+	return time.Duration(p.HeartbeatInterval) * time.Second
+}
+
+func (p *PublishConfig) GetDeadband(objectName, field string) float64 {
+	if deadband, exists := p.Deadbands[objectName+"."+field]; exists {
+		return deadband
+	}
+	return p.Deadbands[field]
+}
+
+func (p *PublishConfig) GetFlattenMode(objectName string) string {
+	if mode, exists := p.FlattenObjects[objectName]; exists {
+		return mode
+	}
+	if mode, exists := p.FlattenObjects[FLATTEN_ALL]; exists {
+		return mode
+	}
+	return FLATTEN_MODE_NONE
+}
+
+func (p *PublishConfig) GetProgressMethod() string {
+	if p.ProgressMethod == "" {
+		return PROGRESS_METHOD_FILE
+	}
+	return p.ProgressMethod
+}
+
+func (p *PublishConfig) Validate() error {
+	if p.MinInterval < 0 {
+		return fmt.Errorf("min interval cannot be negative, got %d", p.MinInterval)
+	}
+
+	if p.HeartbeatInterval < 0 {
+		return fmt.Errorf("heartbeat interval cannot be negative, got %d", p.HeartbeatInterval)
+	}
+
+	if p.HeartbeatInterval > 0 && p.HeartbeatInterval < p.MinInterval {
+		return fmt.Errorf("heartbeat interval (%d) must be greater than or equal to min interval (%d)", p.HeartbeatInterval, p.MinInterval)
+	}
+
+	for key, deadband := range p.Deadbands {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("deadband key cannot be empty")
+		}
+		if deadband < 0 {
+			return fmt.Errorf("deadband for '%s' cannot be negative, got %g", key, deadband)
+		}
+	}
+
+	validModes := []string{FLATTEN_MODE_NONE, FLATTEN_MODE_INDEX, FLATTEN_MODE_AXES}
+	for objectName, mode := range p.FlattenObjects {
+		valid := false
+		for _, validMode := range validModes {
+			if mode == validMode {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid flatten mode '%s' for object '%s', must be one of: %s", mode, objectName, strings.Join(validModes, ", "))
+		}
+	}
+
+	validMethods := []string{PROGRESS_METHOD_FILE, PROGRESS_METHOD_SLICER, PROGRESS_METHOD_FILAMENT}
+	method := p.GetProgressMethod()
+	valid := false
+	for _, validMethod := range validMethods {
+		if method == validMethod {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid progress method '%s', must be one of: %s", method, strings.Join(validMethods, ", "))
+	}
+
 	return nil
 }
 
@@ -417,5 +1115,154 @@ func (l *LoggingConfig) Validate() error {
 		return fmt.Errorf("invalid log format '%s', must be one of: %s", l.Format, strings.Join(validFormats, ", "))
 	}
 
+	for component, componentLevel := range l.Components {
+		if !IsValidLogComponent(component) {
+			return fmt.Errorf("invalid log component '%s', must be one of: %s", component, strings.Join(LogComponents, ", "))
+		}
+		if !IsValidLogLevel(componentLevel) {
+			return fmt.Errorf("invalid log level '%s' for component '%s', must be one of: %s", componentLevel, component, strings.Join(validLevels, ", "))
+		}
+	}
+
+	for i, output := range l.Outputs {
+		if err := output.Validate(); err != nil {
+			return fmt.Errorf("invalid log output %d: %w", i+1, err)
+		}
+	}
+
 	return nil
 }
+
+func (o *LogOutputConfig) Validate() error {
+	validTypes := []string{LOG_OUTPUT_STDOUT, LOG_OUTPUT_STDERR, LOG_OUTPUT_FILE, LOG_OUTPUT_SYSLOG, LOG_OUTPUT_JOURNALD}
+	valid := false
+	for _, validType := range validTypes {
+		if o.Type == validType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid type '%s', must be one of: %s", o.Type, strings.Join(validTypes, ", "))
+	}
+
+	if o.Type == LOG_OUTPUT_FILE && strings.TrimSpace(o.Path) == "" {
+		return fmt.Errorf("file output requires a path")
+	}
+
+	if o.MaxSize < 0 || o.RotateInterval < 0 || o.MaxBackups < 0 || o.MaxAge < 0 {
+		return fmt.Errorf("max_size, rotate_interval, max_backups and max_age cannot be negative")
+	}
+
+	if o.Type != LOG_OUTPUT_FILE && (o.MaxSize > 0 || o.RotateInterval > 0 || o.MaxBackups > 0 || o.MaxAge > 0) {
+		return fmt.Errorf("rotation options are only supported by file outputs")
+	}
+
+	return nil
+}
+
+func IsValidLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "warning", "error":
+		return true
+	}
+	return false
+}
+
+func IsValidLogComponent(component string) bool {
+	return slices.Contains(LogComponents, component)
+}
+
+func (h *HomeAssistantConfig) GetDiscoveryPrefix() string {
+	if strings.TrimSpace(h.DiscoveryPrefix) == "" {
+		return DEFAULT_DISCOVERY_PREFIX
+	}
+	return h.DiscoveryPrefix
+}
+
+func (h *HomeAssistantConfig) Validate() error {
+	if !h.Enabled {
+		return nil
+	}
+
+	prefix := h.GetDiscoveryPrefix()
+	if strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("home assistant discovery prefix should not start or end with '/', got '%s'", prefix)
+	}
+
+	if strings.ContainsAny(prefix, "+#") {
+		return fmt.Errorf("home assistant discovery prefix cannot contain MQTT wildcards, got '%s'", prefix)
+	}
+
+	for _, r := range h.NodeID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return fmt.Errorf("home assistant node ID may only contain letters, digits, '_' and '-', got '%s'", h.NodeID)
+		}
+	}
+
+	return nil
+}
+
+func (h *HTTPConfig) GetAddress() string {
+	host := h.Host
+	if strings.TrimSpace(host) == "" {
+		host = DEFAULT_HTTP_HOST
+	}
+	port := h.Port
+	if port == 0 {
+		port = DEFAULT_HTTP_PORT
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (h *HTTPConfig) Validate() error {
+	if !h.Enabled {
+		return nil
+	}
+
+	if h.Port < 0 || h.Port > 65535 {
+		return fmt.Errorf("http port must be between 1 and 65535, got %d", h.Port)
+	}
+
+	for key, metric := range h.TelemetryMetrics {
+		pattern, field, found := strings.Cut(key, ".")
+		if !found || pattern == "" || field == "" {
+			return fmt.Errorf("telemetry metric key '%s' must be in the form object.field", key)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid object pattern in telemetry metric key '%s': %w", key, err)
+		}
+		if metric != "" && !isValidMetricName(metric) {
+			return fmt.Errorf("invalid metric name '%s' for '%s'", metric, key)
+		}
+	}
+
+	return nil
+}
+
+func (h *HTTPConfig) GetTelemetryMetrics() map[string]string {
+	metrics := make(map[string]string, len(DefaultTelemetryMetrics)+len(h.TelemetryMetrics))
+	for key, metric := range DefaultTelemetryMetrics {
+		metrics[key] = metric
+	}
+	for key, metric := range h.TelemetryMetrics {
+		if metric == "" {
+			delete(metrics, key)
+			continue
+		}
+		metrics[key] = metric
+	}
+	return metrics
+}
+
+func isValidMetricName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
@@ -1,10 +1,19 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMoonrakerConfig_GetMonitoredObjects(t *testing.T) {
@@ -141,6 +150,86 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid environment",
 		},
+		{
+			name: "valid printers",
+			config: Config{
+				Environment: "development",
+				Printers: []PrinterConfig{
+					{Name: "voron", MoonrakerConfig: MoonrakerConfig{Host: "voron.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+					{Name: "ender", Topic: "ender3", MoonrakerConfig: MoonrakerConfig{Host: "ender.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+				},
+				MQTT:    MQTTConfig{Host: "localhost", Port: 1883, ClientID: "test", TopicPrefix: "test"},
+				Logging: LoggingConfig{Level: "info", Format: "text"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "printer without name",
+			config: Config{
+				Environment: "development",
+				Printers: []PrinterConfig{
+					{MoonrakerConfig: MoonrakerConfig{Host: "voron.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+				},
+				MQTT:    MQTTConfig{Host: "localhost", Port: 1883, ClientID: "test", TopicPrefix: "test"},
+				Logging: LoggingConfig{Level: "info", Format: "text"},
+			},
+			wantErr: true,
+			errMsg:  "printer name cannot be empty",
+		},
+		{
+			name: "duplicate printer names",
+			config: Config{
+				Environment: "development",
+				Printers: []PrinterConfig{
+					{Name: "voron", MoonrakerConfig: MoonrakerConfig{Host: "voron.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+					{Name: "voron", Topic: "other", MoonrakerConfig: MoonrakerConfig{Host: "other.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+				},
+				MQTT:    MQTTConfig{Host: "localhost", Port: 1883, ClientID: "test", TopicPrefix: "test"},
+				Logging: LoggingConfig{Level: "info", Format: "text"},
+			},
+			wantErr: true,
+			errMsg:  "duplicate printer name",
+		},
+		{
+			name: "duplicate printer topics",
+			config: Config{
+				Environment: "development",
+				Printers: []PrinterConfig{
+					{Name: "voron", MoonrakerConfig: MoonrakerConfig{Host: "voron.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+					{Name: "ender", Topic: "voron", MoonrakerConfig: MoonrakerConfig{Host: "ender.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+				},
+				MQTT:    MQTTConfig{Host: "localhost", Port: 1883, ClientID: "test", TopicPrefix: "test"},
+				Logging: LoggingConfig{Level: "info", Format: "text"},
+			},
+			wantErr: true,
+			errMsg:  "duplicate printer topic",
+		},
+		{
+			name: "printer topic with separator",
+			config: Config{
+				Environment: "development",
+				Printers: []PrinterConfig{
+					{Name: "voron", Topic: "farm/voron", MoonrakerConfig: MoonrakerConfig{Host: "voron.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+				},
+				MQTT:    MQTTConfig{Host: "localhost", Port: 1883, ClientID: "test", TopicPrefix: "test"},
+				Logging: LoggingConfig{Level: "info", Format: "text"},
+			},
+			wantErr: true,
+			errMsg:  "single topic level",
+		},
+		{
+			name: "reserved printer topic",
+			config: Config{
+				Environment: "development",
+				Printers: []PrinterConfig{
+					{Name: "bridge", MoonrakerConfig: MoonrakerConfig{Host: "voron.local", Port: 7125, Timeout: 30, CallInterval: 2}},
+				},
+				MQTT:    MQTTConfig{Host: "localhost", Port: 1883, ClientID: "test", TopicPrefix: "test"},
+				Logging: LoggingConfig{Level: "info", Format: "text"},
+			},
+			wantErr: true,
+			errMsg:  "reserved",
+		},
 	}
 
 	for _, tt := range tests {
@@ -242,6 +331,55 @@ func TestMoonrakerConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid monitored objects",
 		},
+		{
+			name: "valid login credentials",
+			config: MoonrakerConfig{
+				Host:         "localhost",
+				Port:         7125,
+				Timeout:      30,
+				CallInterval: 2,
+				Username:     "bridge",
+				Password:     "secret",
+				OneshotToken: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "password without username",
+			config: MoonrakerConfig{
+				Host:         "localhost",
+				Port:         7125,
+				Timeout:      30,
+				CallInterval: 2,
+				Password:     "secret",
+			},
+			wantErr: true,
+			errMsg:  "moonraker password requires a username",
+		},
+		{
+			name: "username without password",
+			config: MoonrakerConfig{
+				Host:         "localhost",
+				Port:         7125,
+				Timeout:      30,
+				CallInterval: 2,
+				Username:     "bridge",
+			},
+			wantErr: true,
+			errMsg:  "moonraker username requires a password",
+		},
+		{
+			name: "oneshot token without credentials",
+			config: MoonrakerConfig{
+				Host:         "localhost",
+				Port:         7125,
+				Timeout:      30,
+				CallInterval: 2,
+				OneshotToken: true,
+			},
+			wantErr: true,
+			errMsg:  "moonraker oneshot token requires",
+		},
 	}
 
 	for _, tt := range tests {
@@ -323,6 +461,18 @@ func TestMQTTConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "mqtt topic prefix should not start or end with '/'",
 		},
+		{
+			name: "invalid rpc pattern",
+			config: MQTTConfig{
+				Host:        "localhost",
+				Port:        1883,
+				ClientID:    "test-client",
+				TopicPrefix: "test",
+				RPCAllow:    []string{"server.files.["},
+			},
+			wantErr: true,
+			errMsg:  "mqtt rpc method pattern 'server.files.[' is invalid",
+		},
 	}
 
 	for _, tt := range tests {
@@ -373,6 +523,57 @@ func TestLoggingConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "invalid log format",
 		},
+		{
+			name: "valid outputs",
+			config: LoggingConfig{Level: "info", Format: "json", Outputs: []LogOutputConfig{
+				{Type: LOG_OUTPUT_STDOUT},
+				{Type: LOG_OUTPUT_FILE, Path: "/var/log/moonraker2mqtt.log", MaxSize: 10, MaxBackups: 5, MaxAge: 7, RotateInterval: 24},
+				{Type: LOG_OUTPUT_SYSLOG, Tag: "m2m"},
+				{Type: LOG_OUTPUT_JOURNALD},
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "unknown output type",
+			config:  LoggingConfig{Level: "info", Format: "text", Outputs: []LogOutputConfig{{Type: "kafka"}}},
+			wantErr: true,
+			errMsg:  "invalid type 'kafka'",
+		},
+		{
+			name:    "file output without path",
+			config:  LoggingConfig{Level: "info", Format: "text", Outputs: []LogOutputConfig{{Type: LOG_OUTPUT_FILE}}},
+			wantErr: true,
+			errMsg:  "file output requires a path",
+		},
+		{
+			name:    "negative rotation size",
+			config:  LoggingConfig{Level: "info", Format: "text", Outputs: []LogOutputConfig{{Type: LOG_OUTPUT_FILE, Path: "bridge.log", MaxSize: -1}}},
+			wantErr: true,
+			errMsg:  "cannot be negative",
+		},
+		{
+			name:    "rotation on stdout",
+			config:  LoggingConfig{Level: "info", Format: "text", Outputs: []LogOutputConfig{{Type: LOG_OUTPUT_STDOUT, MaxBackups: 3}}},
+			wantErr: true,
+			errMsg:  "only supported by file outputs",
+		},
+		{
+			name:    "valid component levels",
+			config:  LoggingConfig{Level: "info", Format: "text", Components: map[string]string{"websocket": "debug", "mqtt": "WARNING"}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid component level",
+			config:  LoggingConfig{Level: "info", Format: "text", Components: map[string]string{"websocket": "verbose"}},
+			wantErr: true,
+			errMsg:  "for component 'websocket'",
+		},
+		{
+			name:    "unknown component",
+			config:  LoggingConfig{Level: "info", Format: "text", Components: map[string]string{"websockt": "debug"}},
+			wantErr: true,
+			errMsg:  "invalid log component 'websockt'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -522,3 +723,690 @@ logging:
 		})
 	}
 }
+
+func TestHomeAssistantConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  HomeAssistantConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "disabled config is not validated",
+			config:  HomeAssistantConfig{Enabled: false, DiscoveryPrefix: "/invalid/"},
+			wantErr: false,
+		},
+		{
+			name:    "valid config",
+			config:  HomeAssistantConfig{Enabled: true, DiscoveryPrefix: "homeassistant", NodeID: "voron_24"},
+			wantErr: false,
+		},
+		{
+			name:    "empty prefix uses default",
+			config:  HomeAssistantConfig{Enabled: true},
+			wantErr: false,
+		},
+		{
+			name:    "prefix with trailing slash",
+			config:  HomeAssistantConfig{Enabled: true, DiscoveryPrefix: "homeassistant/"},
+			wantErr: true,
+			errMsg:  "home assistant discovery prefix should not start or end with '/'",
+		},
+		{
+			name:    "prefix with wildcard",
+			config:  HomeAssistantConfig{Enabled: true, DiscoveryPrefix: "home#"},
+			wantErr: true,
+			errMsg:  "home assistant discovery prefix cannot contain MQTT wildcards",
+		},
+		{
+			name:    "invalid node ID",
+			config:  HomeAssistantConfig{Enabled: true, NodeID: "my printer"},
+			wantErr: true,
+			errMsg:  "home assistant node ID may only contain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HomeAssistantConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("HomeAssistantConfig.Validate() error = %v, expected to contain %v", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestMQTTConfig_IsRPCMethodAllowed(t *testing.T) {
+	config := MQTTConfig{
+		RPCAllow: []string{"server.files.*", "machine.*", "printer.info"},
+		RPCDeny:  []string{"machine.reboot", "machine.shutdown"},
+	}
+
+	tests := []struct {
+		method  string
+		allowed bool
+	}{
+		{method: "server.files.list", allowed: true},
+		{method: "server.files.metadata", allowed: true},
+		{method: "printer.info", allowed: true},
+		{method: "machine.system_info", allowed: true},
+		{method: "machine.reboot", allowed: false},
+		{method: "machine.shutdown", allowed: false},
+		{method: "printer.gcode.script", allowed: false},
+		{method: "server.info", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if allowed := config.IsRPCMethodAllowed(tt.method); allowed != tt.allowed {
+				t.Errorf("IsRPCMethodAllowed(%s) = %v, want %v", tt.method, allowed, tt.allowed)
+			}
+		})
+	}
+
+	empty := MQTTConfig{}
+	if empty.IsRPCMethodAllowed("server.info") {
+		t.Error("IsRPCMethodAllowed() should deny every method when the allow-list is empty")
+	}
+}
+
+func TestLoadConfig_Printers(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "printers.yaml")
+	data := `environment: development
+moonraker:
+  host: localhost
+  port: 7125
+  timeout: 15
+  call_interval: 2
+  tls:
+    server_name: moonraker.local
+    min_version: "1.2"
+printers:
+  - name: voron
+    host: voron.local
+  - name: ender
+    topic: ender3
+    host: ender.local
+    port: 7126
+    tls:
+      server_name: ender.local
+mqtt:
+  host: localhost
+  port: 1883
+  client_id: test-client
+  topic_prefix: test
+logging:
+  level: info
+  format: text`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	printers := config.GetPrinters()
+	if len(printers) != 2 {
+		t.Fatalf("GetPrinters() returned %d printers, want 2", len(printers))
+	}
+
+	if printers[0].Host != "voron.local" || printers[0].Port != 7125 || printers[0].Timeout != 15 {
+		t.Errorf("printer voron did not inherit defaults: %+v", printers[0].MoonrakerConfig)
+	}
+	if got := printers[0].GetTopicPrefix("test"); got != "test/voron" {
+		t.Errorf("GetTopicPrefix() = %v, want test/voron", got)
+	}
+
+	if printers[1].Port != 7126 || printers[1].Timeout != 15 {
+		t.Errorf("printer ender did not override port: %+v", printers[1].MoonrakerConfig)
+	}
+	if printers[0].TLS.ServerName != "moonraker.local" || printers[1].TLS.ServerName != "ender.local" {
+		t.Errorf("printers did not resolve tls server name: %+v, %+v", printers[0].TLS, printers[1].TLS)
+	}
+	if printers[1].TLS.MinVersion != "1.2" {
+		t.Errorf("printer ender did not inherit tls min version: %+v", printers[1].TLS)
+	}
+	if got := printers[1].GetTopicPrefix("test"); got != "test/ender3" {
+		t.Errorf("GetTopicPrefix() = %v, want test/ender3", got)
+	}
+}
+
+func TestConfig_GetPrinters_Legacy(t *testing.T) {
+	config := DefaultConfig()
+
+	printers := config.GetPrinters()
+	if len(printers) != 1 {
+		t.Fatalf("GetPrinters() returned %d printers, want 1", len(printers))
+	}
+	if got := printers[0].GetTopicPrefix("moonraker"); got != "moonraker" {
+		t.Errorf("GetTopicPrefix() = %v, want moonraker", got)
+	}
+}
+
+func TestPublishConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PublishConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid config",
+			config:  PublishConfig{OnlyOnChange: true, MinInterval: 1, HeartbeatInterval: 60, Deadbands: map[string]float64{"temperature": 0.5}},
+			wantErr: false,
+		},
+		{
+			name:    "heartbeat disabled",
+			config:  PublishConfig{OnlyOnChange: true, MinInterval: 5},
+			wantErr: false,
+		},
+		{
+			name:    "negative min interval",
+			config:  PublishConfig{MinInterval: -1},
+			wantErr: true,
+			errMsg:  "min interval cannot be negative",
+		},
+		{
+			name:    "heartbeat shorter than min interval",
+			config:  PublishConfig{MinInterval: 10, HeartbeatInterval: 5},
+			wantErr: true,
+			errMsg:  "heartbeat interval (5) must be greater than or equal to min interval (10)",
+		},
+		{
+			name:    "negative deadband",
+			config:  PublishConfig{Deadbands: map[string]float64{"extruder.temperature": -0.5}},
+			wantErr: true,
+			errMsg:  "deadband for 'extruder.temperature' cannot be negative",
+		},
+		{
+			name:    "valid flatten modes",
+			config:  PublishConfig{FlattenObjects: map[string]string{"*": "index", "toolhead": "axes", "webhooks": "none"}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid progress method",
+			config:  PublishConfig{ProgressMethod: "magic"},
+			wantErr: true,
+			errMsg:  "invalid progress method 'magic'",
+		},
+		{
+			name:    "invalid flatten mode",
+			config:  PublishConfig{FlattenObjects: map[string]string{"toolhead": "xyz"}},
+			wantErr: true,
+			errMsg:  "invalid flatten mode 'xyz' for object 'toolhead'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PublishConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("PublishConfig.Validate() error = %v, expected to contain %v", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestPublishConfig_GetDeadband(t *testing.T) {
+	config := PublishConfig{Deadbands: map[string]float64{"temperature": 0.5, "heater_bed.temperature": 1}}
+
+	if got := config.GetDeadband("extruder", "temperature"); got != 0.5 {
+		t.Errorf("GetDeadband(extruder, temperature) = %v, want 0.5", got)
+	}
+	if got := config.GetDeadband("heater_bed", "temperature"); got != 1 {
+		t.Errorf("GetDeadband(heater_bed, temperature) = %v, want 1", got)
+	}
+	if got := config.GetDeadband("toolhead", "position"); got != 0 {
+		t.Errorf("GetDeadband(toolhead, position) = %v, want 0", got)
+	}
+}
+
+func TestParseDeadbands(t *testing.T) {
+	deadbands, err := parseDeadbands("temperature=0.5, extruder.target = 1")
+	if err != nil {
+		t.Fatalf("parseDeadbands() error = %v", err)
+	}
+	if deadbands["temperature"] != 0.5 || deadbands["extruder.target"] != 1 {
+		t.Errorf("parseDeadbands() = %v", deadbands)
+	}
+
+	if _, err := parseDeadbands("temperature"); err == nil {
+		t.Error("parseDeadbands() expected error for missing value")
+	}
+	if _, err := parseDeadbands("temperature=hot"); err == nil {
+		t.Error("parseDeadbands() expected error for invalid number")
+	}
+}
+
+func TestPublishConfig_GetFlattenMode(t *testing.T) {
+	config := PublishConfig{FlattenObjects: map[string]string{"toolhead": FLATTEN_MODE_AXES}}
+	if got := config.GetFlattenMode("toolhead"); got != FLATTEN_MODE_AXES {
+		t.Errorf("GetFlattenMode(toolhead) = %v, want %v", got, FLATTEN_MODE_AXES)
+	}
+	if got := config.GetFlattenMode("extruder"); got != FLATTEN_MODE_NONE {
+		t.Errorf("GetFlattenMode(extruder) = %v, want %v", got, FLATTEN_MODE_NONE)
+	}
+
+	config.FlattenObjects[FLATTEN_ALL] = FLATTEN_MODE_INDEX
+	if got := config.GetFlattenMode("extruder"); got != FLATTEN_MODE_INDEX {
+		t.Errorf("GetFlattenMode(extruder) = %v, want %v", got, FLATTEN_MODE_INDEX)
+	}
+}
+
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "moonraker2mqtt"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestTLSConfig_Build(t *testing.T) {
+	tmpDir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, tmpDir)
+
+	invalidCA := filepath.Join(tmpDir, "invalid.pem")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write invalid CA: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  TLSConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "empty config",
+			config:  TLSConfig{},
+			wantErr: false,
+		},
+		{
+			name: "full config",
+			config: TLSConfig{
+				CAFile:     certFile,
+				CertFile:   certFile,
+				KeyFile:    keyFile,
+				ServerName: "broker.local",
+				MinVersion: "1.3",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "insecure skip verify",
+			config:  TLSConfig{InsecureSkipVerify: true},
+			wantErr: false,
+		},
+		{
+			name:    "invalid min version",
+			config:  TLSConfig{MinVersion: "1.4"},
+			wantErr: true,
+			errMsg:  "tls min version must be one of",
+		},
+		{
+			name:    "missing CA file",
+			config:  TLSConfig{CAFile: filepath.Join(tmpDir, "missing.pem")},
+			wantErr: true,
+			errMsg:  "failed to read tls ca file",
+		},
+		{
+			name:    "invalid CA file",
+			config:  TLSConfig{CAFile: invalidCA},
+			wantErr: true,
+			errMsg:  "contains no valid PEM certificates",
+		},
+		{
+			name:    "cert without key",
+			config:  TLSConfig{CertFile: certFile},
+			wantErr: true,
+			errMsg:  "tls cert file and key file must be set together",
+		},
+		{
+			name:    "mismatched key pair",
+			config:  TLSConfig{CertFile: certFile, KeyFile: invalidCA},
+			wantErr: true,
+			errMsg:  "failed to load tls client certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := tt.config.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TLSConfig.Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tt.errMsg) {
+					t.Errorf("TLSConfig.Build() error = %v, expected to contain %v", err, tt.errMsg)
+				}
+				return
+			}
+			if tlsConfig.ServerName != tt.config.ServerName || tlsConfig.InsecureSkipVerify != tt.config.InsecureSkipVerify {
+				t.Errorf("TLSConfig.Build() = %+v, does not match %+v", tlsConfig, tt.config)
+			}
+			if tt.config.MinVersion == "1.3" && tlsConfig.MinVersion != tls.VersionTLS13 {
+				t.Errorf("TLSConfig.Build() MinVersion = %x, want TLS 1.3", tlsConfig.MinVersion)
+			}
+			if tt.config.CAFile != "" && tlsConfig.RootCAs == nil {
+				t.Error("TLSConfig.Build() did not load the CA bundle")
+			}
+			if tt.config.CertFile != "" && len(tlsConfig.Certificates) != 1 {
+				t.Error("TLSConfig.Build() did not load the client certificate")
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_TLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name: "mqtt tls options without use_tls",
+			modify: func(c *Config) {
+				c.MQTT.TLS.ServerName = "broker.local"
+			},
+			wantErr: true,
+			errMsg:  "mqtt tls options require use_tls to be enabled",
+		},
+		{
+			name: "moonraker tls options without ssl",
+			modify: func(c *Config) {
+				c.Moonraker.TLS.InsecureSkipVerify = true
+			},
+			wantErr: true,
+			errMsg:  "moonraker tls options require ssl to be enabled",
+		},
+		{
+			name: "invalid mqtt min version",
+			modify: func(c *Config) {
+				c.MQTT.UseTLS = true
+				c.MQTT.TLS.MinVersion = "ssl3"
+			},
+			wantErr: true,
+			errMsg:  "invalid mqtt tls config",
+		},
+		{
+			name: "valid tls on both links",
+			modify: func(c *Config) {
+				c.MQTT.UseTLS = true
+				c.MQTT.TLS.MinVersion = "1.2"
+				c.Moonraker.SSL = true
+				c.Moonraker.TLS.ServerName = "printer.local"
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(config)
+
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Config.Validate() error = %v, expected to contain %v", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  HTTPConfig
+		wantErr bool
+	}{
+		{"disabled with invalid port", HTTPConfig{Enabled: false, Port: -1}, false},
+		{"enabled with default port", HTTPConfig{Enabled: true}, false},
+		{"enabled with valid port", HTTPConfig{Enabled: true, Port: 8080}, false},
+		{"enabled with negative port", HTTPConfig{Enabled: true, Port: -1}, true},
+		{"enabled with port too high", HTTPConfig{Enabled: true, Port: 70000}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HTTPConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_GetAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		config HTTPConfig
+		want   string
+	}{
+		{"defaults", HTTPConfig{}, "0.0.0.0:9464"},
+		{"custom", HTTPConfig{Host: "127.0.0.1", Port: 8080}, "127.0.0.1:8080"},
+		{"ipv6", HTTPConfig{Host: "::1", Port: 8080}, "[::1]:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GetAddress(); got != tt.want {
+				t.Errorf("HTTPConfig.GetAddress() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_ValidateTelemetryMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics map[string]string
+		wantErr bool
+	}{
+		{"valid mapping", map[string]string{"extruder*.temperature": "klipper_temperature_celsius"}, false},
+		{"nested field", map[string]string{"mcu.last_stats.mcu_awake": "klipper_mcu_awake"}, false},
+		{"disabled default", map[string]string{"fan.speed": ""}, false},
+		{"missing field", map[string]string{"extruder": "klipper_temperature_celsius"}, true},
+		{"invalid pattern", map[string]string{"extruder[.temperature": "klipper_temperature_celsius"}, true},
+		{"invalid metric name", map[string]string{"fan.speed": "fan-speed"}, true},
+		{"metric name starting with digit", map[string]string{"fan.speed": "1fan"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := HTTPConfig{Enabled: true, TelemetryMetrics: tt.metrics}
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HTTPConfig.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_GetTelemetryMetrics(t *testing.T) {
+	config := HTTPConfig{TelemetryMetrics: map[string]string{
+		"fan.speed":           "",
+		"toolhead.position":   "printer_position_mm",
+		"chamber.temperature": "klipper_chamber_celsius",
+	}}
+
+	metrics := config.GetTelemetryMetrics()
+
+	if _, exists := metrics["fan.speed"]; exists {
+		t.Error("GetTelemetryMetrics() kept a default disabled with an empty name")
+	}
+	if metrics["toolhead.position"] != "printer_position_mm" {
+		t.Errorf("GetTelemetryMetrics()[toolhead.position] = %s, want printer_position_mm", metrics["toolhead.position"])
+	}
+	if metrics["chamber.temperature"] != "klipper_chamber_celsius" {
+		t.Errorf("GetTelemetryMetrics()[chamber.temperature] = %s, want klipper_chamber_celsius", metrics["chamber.temperature"])
+	}
+	if metrics["heater_bed.temperature"] != "klipper_temperature_celsius" {
+		t.Errorf("GetTelemetryMetrics()[heater_bed.temperature] = %s, want the default", metrics["heater_bed.temperature"])
+	}
+}
+
+func TestParseLogOutputs(t *testing.T) {
+	got := parseLogOutputs("stdout, file:/var/log/moonraker2mqtt.log,syslog:/dev/log")
+	want := []LogOutputConfig{
+		{Type: LOG_OUTPUT_STDOUT},
+		{Type: LOG_OUTPUT_FILE, Path: "/var/log/moonraker2mqtt.log"},
+		{Type: LOG_OUTPUT_SYSLOG, Path: "/dev/log"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("parseLogOutputs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseLogOutputs()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConfig_Diff(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(cfg *Config)
+		wantEmpty   bool
+		wantMQTT    bool
+		wantLogging bool
+		wantPrinter PrinterDiff
+		wantRestart []string
+	}{
+		{
+			name:      "unchanged",
+			modify:    func(cfg *Config) {},
+			wantEmpty: true,
+		},
+		{
+			name:        "log level",
+			modify:      func(cfg *Config) { cfg.Logging.Level = "debug" },
+			wantLogging: true,
+		},
+		{
+			name:     "mqtt host",
+			modify:   func(cfg *Config) { cfg.MQTT.Host = "broker.local" },
+			wantMQTT: true,
+		},
+		{
+			name:        "moonraker host",
+			modify:      func(cfg *Config) { cfg.Moonraker.Host = "voron.local" },
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, Connection: true, Changed: true},
+		},
+		{
+			name:        "call interval",
+			modify:      func(cfg *Config) { cfg.Moonraker.CallInterval = 10 },
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, CallInterval: true, Changed: true},
+		},
+		{
+			name:        "monitored objects",
+			modify:      func(cfg *Config) { cfg.Moonraker.MonitoredObjects = `{"print_stats": null}` },
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, MonitoredObjects: true, Changed: true},
+		},
+		{
+			name:        "topic prefix",
+			modify:      func(cfg *Config) { cfg.MQTT.TopicPrefix = "printers" },
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, Topic: true},
+		},
+		{
+			name:        "publish settings",
+			modify:      func(cfg *Config) { cfg.MQTT.QoS = 1; cfg.Publish.MinInterval = 5 },
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, Settings: true},
+		},
+		{
+			name:        "availability",
+			modify:      func(cfg *Config) { cfg.MQTT.AvailabilityEnabled = !cfg.MQTT.AvailabilityEnabled },
+			wantMQTT:    true,
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, Settings: true},
+		},
+		{
+			name:        "telemetry metrics",
+			modify:      func(cfg *Config) { cfg.HTTP.TelemetryMetrics = map[string]string{"fan.rpm": "klipper_fan_rpm"} },
+			wantPrinter: PrinterDiff{Name: DEFAULT_PRINTER_NAME, Telemetry: true},
+		},
+		{
+			name:        "restart required",
+			modify:      func(cfg *Config) { cfg.MQTT.ControlEnabled = !cfg.MQTT.ControlEnabled; cfg.HTTP.Port = 9000 },
+			wantRestart: []string{"mqtt.control_enabled", "http"},
+		},
+		{
+			name:        "printers added",
+			modify:      func(cfg *Config) { cfg.Printers = []PrinterConfig{{Name: "voron", MoonrakerConfig: cfg.Moonraker}} },
+			wantRestart: []string{"printers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, next := DefaultConfig(), DefaultConfig()
+			tt.modify(next)
+
+			diff := current.Diff(next)
+
+			if diff.IsEmpty() != tt.wantEmpty {
+				t.Errorf("IsEmpty() = %t, want %t", diff.IsEmpty(), tt.wantEmpty)
+			}
+			if diff.MQTTConnection != tt.wantMQTT {
+				t.Errorf("MQTTConnection = %t, want %t", diff.MQTTConnection, tt.wantMQTT)
+			}
+			if diff.Logging != tt.wantLogging {
+				t.Errorf("Logging = %t, want %t", diff.Logging, tt.wantLogging)
+			}
+			if strings.Join(diff.RestartRequired, ",") != strings.Join(tt.wantRestart, ",") {
+				t.Errorf("RestartRequired = %v, want %v", diff.RestartRequired, tt.wantRestart)
+			}
+
+			var gotPrinter PrinterDiff
+			if len(diff.Printers) > 0 {
+				gotPrinter = diff.Printers[0]
+			}
+			if gotPrinter != tt.wantPrinter {
+				t.Errorf("Printers[0] = %+v, want %+v", gotPrinter, tt.wantPrinter)
+			}
+		})
+	}
+}
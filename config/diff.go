@@ -0,0 +1,113 @@
+package config
+
+import (
+	"reflect"
+)
+
+func (c *Config) Diff(next *Config) ConfigDiff {
+	var diff ConfigDiff
+
+	diff.Logging = c.Logging.Level != next.Logging.Level || !reflect.DeepEqual(c.Logging.Components, next.Logging.Components)
+	if c.Logging.Format != next.Logging.Format {
+		diff.RestartRequired = append(diff.RestartRequired, "logging.format")
+	}
+	if !reflect.DeepEqual(c.Logging.Outputs, next.Logging.Outputs) {
+		diff.RestartRequired = append(diff.RestartRequired, "logging.outputs")
+	}
+
+	diff.MQTTConnection = !reflect.DeepEqual(c.MQTT.connection(), next.MQTT.connection()) ||
+		c.MQTT.AvailabilityEnabled != next.MQTT.AvailabilityEnabled
+	diff.MQTTPolicy = c.MQTT.AutoReconnect != next.MQTT.AutoReconnect || c.MQTT.MaxReconnectAttempts != next.MQTT.MaxReconnectAttempts
+	diff.TopicPrefix = c.MQTT.TopicPrefix != next.MQTT.TopicPrefix
+	diff.MQTTSettings = !reflect.DeepEqual(c.MQTT.settings(), next.MQTT.settings())
+	if c.MQTT.ControlEnabled != next.MQTT.ControlEnabled {
+		diff.RestartRequired = append(diff.RestartRequired, "mqtt.control_enabled")
+	}
+
+	diff.Publish = !reflect.DeepEqual(c.Publish, next.Publish)
+	diff.HomeAssistant = c.HomeAssistant != next.HomeAssistant
+	settings := diff.MQTTSettings || diff.Publish || diff.HomeAssistant
+
+	diff.Telemetry = !reflect.DeepEqual(c.HTTP.GetTelemetryMetrics(), next.HTTP.GetTelemetryMetrics())
+
+	current, updated := c.GetPrinters(), next.GetPrinters()
+	if printerNames(current) != printerNames(updated) {
+		diff.RestartRequired = append(diff.RestartRequired, "printers")
+	} else {
+		for i := range current {
+			printerDiff := current[i].diff(&updated[i])
+			printerDiff.Topic = printerDiff.Topic || diff.TopicPrefix
+			printerDiff.Settings = settings
+			printerDiff.Telemetry = diff.Telemetry
+			if printerDiff.Changed || printerDiff.Topic || printerDiff.Settings || printerDiff.Telemetry {
+				diff.Printers = append(diff.Printers, printerDiff)
+			}
+		}
+	}
+
+	if c.HTTP.Enabled != next.HTTP.Enabled || c.HTTP.Host != next.HTTP.Host || c.HTTP.Port != next.HTTP.Port || c.HTTP.Telemetry != next.HTTP.Telemetry {
+		diff.RestartRequired = append(diff.RestartRequired, "http")
+	}
+
+	return diff
+}
+
+func (d *ConfigDiff) IsEmpty() bool {
+	return !d.Logging && !d.MQTTConnection && !d.MQTTPolicy && !d.TopicPrefix && !d.MQTTSettings &&
+		!d.Publish && !d.HomeAssistant && !d.Telemetry && len(d.Printers) == 0 && len(d.RestartRequired) == 0
+}
+
+func (m *MQTTConfig) connection() MQTTConfig {
+	return MQTTConfig{
+		Host:     m.Host,
+		Port:     m.Port,
+		Username: m.Username,
+		Password: m.Password,
+		UseTLS:   m.UseTLS,
+		ClientID: m.ClientID,
+		TLS:      m.TLS,
+	}
+}
+
+func (m *MQTTConfig) settings() MQTTConfig {
+	return MQTTConfig{
+		QoS:                 m.QoS,
+		Retain:              m.Retain,
+		CommandsEnabled:     m.CommandsEnabled,
+		EstopEnabled:        m.EstopEnabled,
+		AvailabilityEnabled: m.AvailabilityEnabled,
+		RPCAllow:            m.RPCAllow,
+		RPCDeny:             m.RPCDeny,
+	}
+}
+
+func (p *PrinterConfig) diff(next *PrinterConfig) PrinterDiff {
+	diff := PrinterDiff{
+		Name:             p.Name,
+		Policy:           p.AutoReconnect != next.AutoReconnect || p.MaxReconnectAttempts != next.MaxReconnectAttempts,
+		Topic:            p.Topic != next.Topic,
+		CallInterval:     p.CallInterval != next.CallInterval,
+		MonitoredObjects: p.MonitoredObjects != next.MonitoredObjects,
+		Changed:          *p != *next,
+	}
+
+	current, updated := p.MoonrakerConfig, next.MoonrakerConfig
+	for _, m := range []*MoonrakerConfig{&current, &updated} {
+		m.AutoReconnect = false
+		m.MaxReconnectAttempts = 0
+		m.CallInterval = 0
+		m.MonitoredObjects = ""
+		m.PollingFallback = false
+	}
+	diff.Connection = current != updated
+
+	return diff
+}
+
+func printerNames(printers []PrinterConfig) string {
+	names := ""
+	for _, printer := range printers {
+		names += printer.Name + "\n"
+	}
+	return names
+}
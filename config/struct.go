@@ -1,40 +1,137 @@
 package config
 
 type Config struct {
-	Environment string          `yaml:"environment" env:"ENVIRONMENT"`
-	Moonraker   MoonrakerConfig `yaml:"moonraker"`
-	MQTT        MQTTConfig      `yaml:"mqtt"`
-	Logging     LoggingConfig   `yaml:"logging"`
+	Environment   string              `yaml:"environment" env:"ENVIRONMENT"`
+	Moonraker     MoonrakerConfig     `yaml:"moonraker"`
+	Printers      []PrinterConfig     `yaml:"printers,omitempty"`
+	MQTT          MQTTConfig          `yaml:"mqtt"`
+	Publish       PublishConfig       `yaml:"publish"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	HomeAssistant HomeAssistantConfig `yaml:"home_assistant"`
+	HTTP          HTTPConfig          `yaml:"http"`
 }
 
 type MoonrakerConfig struct {
-	Host                 string `yaml:"host" env:"MOONRAKER_HOST"`
-	Port                 int    `yaml:"port" env:"MOONRAKER_PORT"`
-	APIKey               string `yaml:"api_key" env:"MOONRAKER_API_KEY"`
-	SSL                  bool   `yaml:"ssl" env:"MOONRAKER_SSL"`
-	Timeout              int    `yaml:"timeout" env:"MOONRAKER_TIMEOUT"`
-	AutoReconnect        bool   `yaml:"auto_reconnect" env:"MOONRAKER_AUTO_RECONNECT"`
-	MaxReconnectAttempts int    `yaml:"max_reconnect_attempts" env:"MOONRAKER_MAX_RECONNECT_ATTEMPTS"`
-	CallInterval         int    `yaml:"call_interval" env:"MOONRAKER_CALL_INTERVAL"`
-	MonitoredObjects     string `yaml:"monitored_objects" env:"MOONRAKER_MONITORED_OBJECTS"`
+	Host                 string    `yaml:"host" env:"MOONRAKER_HOST"`
+	Port                 int       `yaml:"port" env:"MOONRAKER_PORT"`
+	APIKey               string    `yaml:"api_key" env:"MOONRAKER_API_KEY"`
+	SSL                  bool      `yaml:"ssl" env:"MOONRAKER_SSL"`
+	Timeout              int       `yaml:"timeout" env:"MOONRAKER_TIMEOUT"`
+	AutoReconnect        bool      `yaml:"auto_reconnect" env:"MOONRAKER_AUTO_RECONNECT"`
+	MaxReconnectAttempts int       `yaml:"max_reconnect_attempts" env:"MOONRAKER_MAX_RECONNECT_ATTEMPTS"`
+	CallInterval         int       `yaml:"call_interval" env:"MOONRAKER_CALL_INTERVAL"`
+	MonitoredObjects     string    `yaml:"monitored_objects" env:"MOONRAKER_MONITORED_OBJECTS"`
+	PollingFallback      bool      `yaml:"polling_fallback" env:"MOONRAKER_POLLING_FALLBACK"`
+	KeepaliveInterval    int       `yaml:"keepalive_interval" env:"MOONRAKER_KEEPALIVE_INTERVAL"`
+	LivenessTimeout      int       `yaml:"liveness_timeout" env:"MOONRAKER_LIVENESS_TIMEOUT"`
+	Username             string    `yaml:"username" env:"MOONRAKER_USERNAME"`
+	Password             string    `yaml:"password" env:"MOONRAKER_PASSWORD"`
+	AuthSource           string    `yaml:"auth_source" env:"MOONRAKER_AUTH_SOURCE"`
+	OneshotToken         bool      `yaml:"oneshot_token" env:"MOONRAKER_ONESHOT_TOKEN"`
+	ClientName           string    `yaml:"client_name" env:"MOONRAKER_CLIENT_NAME"`
+	ClientType           string    `yaml:"client_type" env:"MOONRAKER_CLIENT_TYPE"`
+	TLS                  TLSConfig `yaml:"tls"`
+}
+
+type PrinterConfig struct {
+	Name            string `yaml:"name"`
+	Topic           string `yaml:"topic,omitempty"`
+	MoonrakerConfig `yaml:",inline"`
 }
 
 type MQTTConfig struct {
-	Host                 string `yaml:"host" env:"MQTT_HOST"`
-	Port                 int    `yaml:"port" env:"MQTT_PORT"`
-	Username             string `yaml:"username" env:"MQTT_USERNAME"`
-	Password             string `yaml:"password" env:"MQTT_PASSWORD"`
-	UseTLS               bool   `yaml:"use_tls" env:"MQTT_USE_TLS"`
-	ClientID             string `yaml:"client_id" env:"MQTT_CLIENT_ID"`
-	TopicPrefix          string `yaml:"topic_prefix" env:"MQTT_TOPIC_PREFIX"`
-	QoS                  byte   `yaml:"qos" env:"MQTT_QOS"`
-	Retain               bool   `yaml:"retain" env:"MQTT_RETAIN"`
-	AutoReconnect        bool   `yaml:"auto_reconnect" env:"MQTT_AUTO_RECONNECT"`
-	MaxReconnectAttempts int    `yaml:"max_reconnect_attempts" env:"MQTT_MAX_RECONNECT_ATTEMPTS"`
-	CommandsEnabled      bool   `yaml:"commands_enabled" env:"MQTT_COMMANDS_ENABLED"`
+	Host                 string    `yaml:"host" env:"MQTT_HOST"`
+	Port                 int       `yaml:"port" env:"MQTT_PORT"`
+	Username             string    `yaml:"username" env:"MQTT_USERNAME"`
+	Password             string    `yaml:"password" env:"MQTT_PASSWORD"`
+	UseTLS               bool      `yaml:"use_tls" env:"MQTT_USE_TLS"`
+	ClientID             string    `yaml:"client_id" env:"MQTT_CLIENT_ID"`
+	TopicPrefix          string    `yaml:"topic_prefix" env:"MQTT_TOPIC_PREFIX"`
+	QoS                  byte      `yaml:"qos" env:"MQTT_QOS"`
+	Retain               bool      `yaml:"retain" env:"MQTT_RETAIN"`
+	AutoReconnect        bool      `yaml:"auto_reconnect" env:"MQTT_AUTO_RECONNECT"`
+	MaxReconnectAttempts int       `yaml:"max_reconnect_attempts" env:"MQTT_MAX_RECONNECT_ATTEMPTS"`
+	CommandsEnabled      bool      `yaml:"commands_enabled" env:"MQTT_COMMANDS_ENABLED"`
+	EstopEnabled         bool      `yaml:"estop_enabled" env:"MQTT_ESTOP_ENABLED"`
+	ControlEnabled       bool      `yaml:"control_enabled" env:"MQTT_CONTROL_ENABLED"`
+	AvailabilityEnabled  bool      `yaml:"availability_enabled" env:"MQTT_AVAILABILITY_ENABLED"`
+	RPCAllow             []string  `yaml:"rpc_allow" env:"MQTT_RPC_ALLOW"`
+	RPCDeny              []string  `yaml:"rpc_deny" env:"MQTT_RPC_DENY"`
+	TLS                  TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig is shared by both connections, so its environment variables are
+// read with a MOONRAKER_TLS_ or MQTT_TLS_ prefix by overrideTLSWithEnv.
+type TLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	MinVersion         string `yaml:"min_version"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+type PublishConfig struct {
+	OnlyOnChange      bool               `yaml:"only_on_change" env:"PUBLISH_ONLY_ON_CHANGE"`
+	MinInterval       int                `yaml:"min_interval" env:"PUBLISH_MIN_INTERVAL"`
+	HeartbeatInterval int                `yaml:"heartbeat_interval" env:"PUBLISH_HEARTBEAT_INTERVAL"`
+	Deadbands         map[string]float64 `yaml:"deadbands" env:"PUBLISH_DEADBANDS"`
+	FlattenObjects    map[string]string  `yaml:"flatten_objects" env:"PUBLISH_FLATTEN_OBJECTS"`
+	ProgressMethod    string             `yaml:"progress_method" env:"PUBLISH_PROGRESS_METHOD"`
 }
 
 type LoggingConfig struct {
-	Level  string `yaml:"level" env:"LOG_LEVEL"`
-	Format string `yaml:"format" env:"LOG_FORMAT"`
+	Level      string            `yaml:"level" env:"LOG_LEVEL"`
+	Format     string            `yaml:"format" env:"LOG_FORMAT"`
+	Outputs    []LogOutputConfig `yaml:"outputs" env:"LOG_OUTPUTS"`
+	Components map[string]string `yaml:"components" env:"LOG_COMPONENTS"`
+}
+
+type LogOutputConfig struct {
+	Type           string `yaml:"type"`
+	Path           string `yaml:"path,omitempty"`
+	Tag            string `yaml:"tag,omitempty"`
+	MaxSize        int    `yaml:"max_size,omitempty"`
+	RotateInterval int    `yaml:"rotate_interval,omitempty"`
+	MaxBackups     int    `yaml:"max_backups,omitempty"`
+	MaxAge         int    `yaml:"max_age,omitempty"`
+}
+
+type HomeAssistantConfig struct {
+	Enabled         bool   `yaml:"enabled" env:"HOMEASSISTANT_ENABLED"`
+	DiscoveryPrefix string `yaml:"discovery_prefix" env:"HOMEASSISTANT_DISCOVERY_PREFIX"`
+	NodeID          string `yaml:"node_id" env:"HOMEASSISTANT_NODE_ID"`
+}
+
+type HTTPConfig struct {
+	Enabled          bool              `yaml:"enabled" env:"HTTP_ENABLED"`
+	Host             string            `yaml:"host" env:"HTTP_HOST"`
+	Port             int               `yaml:"port" env:"HTTP_PORT"`
+	Telemetry        bool              `yaml:"telemetry" env:"HTTP_TELEMETRY"`
+	TelemetryMetrics map[string]string `yaml:"telemetry_metrics" env:"HTTP_TELEMETRY_METRICS"`
+}
+
+type ConfigDiff struct {
+	Logging         bool
+	MQTTConnection  bool
+	MQTTPolicy      bool
+	TopicPrefix     bool
+	MQTTSettings    bool
+	Publish         bool
+	HomeAssistant   bool
+	Telemetry       bool
+	Printers        []PrinterDiff
+	RestartRequired []string
+}
+
+type PrinterDiff struct {
+	Name             string
+	Connection       bool
+	Policy           bool
+	Topic            bool
+	CallInterval     bool
+	MonitoredObjects bool
+	Settings         bool
+	Telemetry        bool
+	Changed          bool
 }
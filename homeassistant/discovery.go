@@ -0,0 +1,316 @@
+package homeassistant
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func NewDiscovery(discoveryPrefix, nodeID, topicPrefix string, commandsEnabled bool, availabilityTopics []string) *Discovery {
+	return &Discovery{
+		discoveryPrefix:    discoveryPrefix,
+		nodeID:             nodeID,
+		topicPrefix:        topicPrefix,
+		commandsEnabled:    commandsEnabled,
+		availabilityTopics: availabilityTopics,
+	}
+}
+
+func (d *Discovery) StatusTopic() string {
+	return fmt.Sprintf("%s/status", d.discoveryPrefix)
+}
+
+func (d *Discovery) Messages(printer *PrinterDescription) ([]Message, error) {
+	nodeID := d.resolveNodeID(printer)
+	entities := d.Entities(printer)
+
+	messages := make([]Message, 0, len(entities))
+	for _, entity := range entities {
+		payload, err := json.Marshal(entity.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal discovery config for %s: %w", entity.ObjectID, err)
+		}
+
+		messages = append(messages, Message{
+			Topic:   fmt.Sprintf("%s/%s/%s/%s/config", d.discoveryPrefix, entity.Component, nodeID, entity.ObjectID),
+			Payload: payload,
+		})
+	}
+
+	return messages, nil
+}
+
+func (d *Discovery) Entities(printer *PrinterDescription) []Entity {
+	nodeID := d.resolveNodeID(printer)
+	device := Device{
+		Identifiers:  []string{nodeID},
+		Name:         d.deviceName(printer),
+		Manufacturer: "Klipper",
+		Model:        "Moonraker",
+		SWVersion:    printer.SoftwareVersion,
+	}
+
+	entities := []Entity{
+		d.newEntity(nodeID, device, COMPONENT_SENSOR, "klipper_state", EntityConfig{
+			Name:       "Klipper State",
+			Icon:       "mdi:printer-3d",
+			StateTopic: d.topic("klipper/state"),
+		}),
+	}
+
+	if d.monitors(printer, "print_stats", "state") {
+		entities = append(entities, d.newEntity(nodeID, device, COMPONENT_SENSOR, "print_state", EntityConfig{
+			Name:          "Print State",
+			Icon:          "mdi:printer-3d-nozzle",
+			StateTopic:    d.topic("objects/print_stats"),
+			ValueTemplate: "{{ value_json.state }}",
+		}))
+	}
+
+	if d.monitors(printer, "print_stats", "filename") {
+		entities = append(entities, d.newEntity(nodeID, device, COMPONENT_SENSOR, "print_filename", EntityConfig{
+			Name:          "Print Filename",
+			Icon:          "mdi:file",
+			StateTopic:    d.topic("objects/print_stats"),
+			ValueTemplate: "{{ value_json.filename }}",
+		}))
+	}
+
+	entities = append(entities,
+		d.newEntity(nodeID, device, COMPONENT_SENSOR, "print_progress", EntityConfig{
+			Name:              "Print Progress",
+			Icon:              "mdi:progress-clock",
+			StateClass:        "measurement",
+			UnitOfMeasurement: "%",
+			StateTopic:        d.topic("job/progress"),
+		}),
+		d.newEntity(nodeID, device, COMPONENT_SENSOR, "print_remaining", EntityConfig{
+			Name:              "Print Time Remaining",
+			Icon:              "mdi:timer-sand",
+			DeviceClass:       "duration",
+			UnitOfMeasurement: "s",
+			StateTopic:        d.topic("job/remaining_seconds"),
+		}),
+		d.newEntity(nodeID, device, COMPONENT_SENSOR, "print_eta", EntityConfig{
+			Name:        "Print ETA",
+			Icon:        "mdi:clock-end",
+			DeviceClass: "timestamp",
+			StateTopic:  d.topic("job/eta"),
+		}),
+		d.newEntity(nodeID, device, COMPONENT_SENSOR, "print_layer", EntityConfig{
+			Name:          "Print Layer",
+			Icon:          "mdi:layers-triple",
+			StateTopic:    d.topic("job/layer"),
+			ValueTemplate: "{{ value_json.current }}",
+		}),
+	)
+
+	for _, objectName := range sortedKeys(printer.MonitoredObjects) {
+		if !isTemperatureObject(objectName) || !d.available(printer, objectName) {
+			continue
+		}
+
+		objectID := SanitizeID(objectName)
+
+		if d.monitors(printer, objectName, "temperature") {
+			entities = append(entities, d.newEntity(nodeID, device, COMPONENT_SENSOR, objectID+"_temperature", EntityConfig{
+				Name:              fmt.Sprintf("%s Temperature", displayName(objectName)),
+				DeviceClass:       "temperature",
+				StateClass:        "measurement",
+				UnitOfMeasurement: "°C",
+				StateTopic:        d.topic("objects/" + objectName),
+				ValueTemplate:     "{{ value_json.temperature }}",
+			}))
+		}
+
+		if d.commandsEnabled && isHeater(objectName) && d.monitors(printer, objectName, "temperature") && d.monitors(printer, objectName, "target") {
+			minTemperature := 0.0
+			entities = append(entities, d.newEntity(nodeID, device, COMPONENT_CLIMATE, objectID, EntityConfig{
+				Name:                       displayName(objectName),
+				Icon:                       "mdi:thermometer",
+				CurrentTemperatureTopic:    d.topic("objects/" + objectName),
+				CurrentTemperatureTemplate: "{{ value_json.temperature }}",
+				TemperatureStateTopic:      d.topic("objects/" + objectName),
+				TemperatureStateTemplate:   "{{ value_json.target }}",
+				TemperatureCommandTopic:    d.topic("commands"),
+				TemperatureCommandTemplate: fmt.Sprintf(`{"command": "set_temperature", "params": {"heater": "%s", "target": {{ value }}}}`, heaterName(objectName)),
+				TemperatureUnit:            "C",
+				Modes:                      []string{"heat"},
+				MinTemp:                    &minTemperature,
+				MaxTemp:                    maxTemperature(objectName),
+				TempStep:                   1,
+				Precision:                  0.1,
+			}))
+		}
+	}
+
+	if d.commandsEnabled {
+		buttons := []struct {
+			objectID string
+			name     string
+			icon     string
+			command  string
+		}{
+			{"emergency_stop", "Emergency Stop", "mdi:alert-octagon", "emergency_stop"},
+			{"restart", "Restart", "mdi:restart", "restart"},
+			{"firmware_restart", "Firmware Restart", "mdi:restart-alert", "firmware_restart"},
+		}
+
+		for _, button := range buttons {
+			entities = append(entities, d.newEntity(nodeID, device, COMPONENT_BUTTON, button.objectID, EntityConfig{
+				Name:         button.name,
+				Icon:         button.icon,
+				CommandTopic: d.topic("commands"),
+				PayloadPress: fmt.Sprintf(`{"command": "%s"}`, button.command),
+			}))
+		}
+	}
+
+	return entities
+}
+
+func (d *Discovery) newEntity(nodeID string, device Device, component, objectID string, config EntityConfig) Entity {
+	config.UniqueID = fmt.Sprintf("%s_%s", nodeID, objectID)
+	config.ObjectID = fmt.Sprintf("%s_%s", nodeID, objectID)
+	config.Device = device
+
+	for _, topic := range d.availabilityTopics {
+		config.Availability = append(config.Availability, Availability{Topic: topic})
+	}
+	if len(config.Availability) > 1 {
+		config.AvailabilityMode = "all"
+	}
+
+	return Entity{
+		Component: component,
+		ObjectID:  objectID,
+		Config:    config,
+	}
+}
+
+func (d *Discovery) topic(suffix string) string {
+	return fmt.Sprintf("%s/%s", d.topicPrefix, suffix)
+}
+
+func (d *Discovery) resolveNodeID(printer *PrinterDescription) string {
+	if d.nodeID != "" {
+		return SanitizeID(d.nodeID)
+	}
+	if printer.Hostname != "" {
+		return SanitizeID(printer.Hostname)
+	}
+	return SanitizeID(d.topicPrefix)
+}
+
+func (d *Discovery) deviceName(printer *PrinterDescription) string {
+	if printer.Hostname != "" {
+		return printer.Hostname
+	}
+	return d.resolveNodeID(printer)
+}
+
+func (d *Discovery) monitors(printer *PrinterDescription, objectName, field string) bool {
+	if !d.available(printer, objectName) {
+		return false
+	}
+
+	fields, exists := printer.MonitoredObjects[objectName]
+	if !exists {
+		return false
+	}
+
+	switch v := fields.(type) {
+	case nil:
+		return true
+	case []any:
+		for _, item := range v {
+			if item == field {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range v {
+			if item == field {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (d *Discovery) available(printer *PrinterDescription, objectName string) bool {
+	if len(printer.AvailableObjects) == 0 {
+		return true
+	}
+
+	for _, available := range printer.AvailableObjects {
+		if available == objectName {
+			return true
+		}
+	}
+
+	return false
+}
+
+func SanitizeID(value string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+	return builder.String()
+}
+
+func isHeater(objectName string) bool {
+	if objectName == "heater_bed" || strings.HasPrefix(objectName, "heater_generic ") {
+		return true
+	}
+	return strings.HasPrefix(objectName, "extruder") && !strings.Contains(objectName, " ")
+}
+
+func isTemperatureObject(objectName string) bool {
+	return isHeater(objectName) ||
+		strings.HasPrefix(objectName, "temperature_sensor ") ||
+		strings.HasPrefix(objectName, "temperature_fan ")
+}
+
+func heaterName(objectName string) string {
+	if _, name, found := strings.Cut(objectName, " "); found {
+		return name
+	}
+	return objectName
+}
+
+func displayName(objectName string) string {
+	words := strings.Fields(strings.ReplaceAll(heaterName(objectName), "_", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+func maxTemperature(objectName string) float64 {
+	switch {
+	case strings.HasPrefix(objectName, "extruder"):
+		return 300
+	case objectName == "heater_bed":
+		return 120
+	default:
+		return 100
+	}
+}
+
+func sortedKeys(objects map[string]any) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
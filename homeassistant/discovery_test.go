@@ -0,0 +1,136 @@
+package homeassistant
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiscovery_Entities(t *testing.T) {
+	printer := &PrinterDescription{
+		Hostname:         "Voron 2.4",
+		SoftwareVersion:  "v0.12.0",
+		AvailableObjects: []string{"print_stats", "extruder", "heater_bed", "heater_generic chamber", "toolhead"},
+		MonitoredObjects: map[string]any{
+			"print_stats":            nil,
+			"toolhead":               []any{"position"},
+			"extruder":               []any{"temperature", "target"},
+			"heater_bed":             []any{"temperature"},
+			"heater_generic chamber": nil,
+			"temperature_sensor mcu": nil,
+		},
+	}
+
+	tests := []struct {
+		name            string
+		commandsEnabled bool
+		expected        []string
+		unexpected      []string
+	}{
+		{
+			name:            "commands enabled",
+			commandsEnabled: true,
+			expected: []string{
+				"sensor/klipper_state",
+				"sensor/print_state",
+				"sensor/print_progress",
+				"sensor/print_eta",
+				"sensor/extruder_temperature",
+				"sensor/heater_bed_temperature",
+				"sensor/heater_generic_chamber_temperature",
+				"climate/extruder",
+				"climate/heater_generic_chamber",
+				"button/emergency_stop",
+				"button/firmware_restart",
+			},
+			unexpected: []string{
+				"climate/heater_bed",
+				"sensor/temperature_sensor_mcu_temperature",
+			},
+		},
+		{
+			name:            "commands disabled",
+			commandsEnabled: false,
+			expected: []string{
+				"sensor/extruder_temperature",
+			},
+			unexpected: []string{
+				"climate/extruder",
+				"button/emergency_stop",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovery := NewDiscovery("homeassistant", "", "moonraker", tt.commandsEnabled, nil)
+
+			found := make(map[string]Entity)
+			for _, entity := range discovery.Entities(printer) {
+				found[entity.Component+"/"+entity.ObjectID] = entity
+			}
+
+			for _, key := range tt.expected {
+				if _, exists := found[key]; !exists {
+					t.Errorf("Entities() missing expected entity: %s", key)
+				}
+			}
+
+			for _, key := range tt.unexpected {
+				if _, exists := found[key]; exists {
+					t.Errorf("Entities() returned unexpected entity: %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscovery_Messages(t *testing.T) {
+	discovery := NewDiscovery("homeassistant", "", "moonraker", true, []string{"moonraker/bridge/availability", "moonraker/availability"})
+	printer := &PrinterDescription{
+		Hostname:         "Voron 2.4",
+		SoftwareVersion:  "v0.12.0",
+		MonitoredObjects: map[string]any{"extruder": nil},
+	}
+
+	messages, err := discovery.Messages(printer)
+	if err != nil {
+		t.Fatalf("Messages() error = %v", err)
+	}
+
+	var climate *Message
+	for i := range messages {
+		if messages[i].Topic == "homeassistant/climate/voron_2_4/extruder/config" {
+			climate = &messages[i]
+		}
+	}
+
+	if climate == nil {
+		t.Fatal("Messages() missing climate discovery topic for extruder")
+	}
+
+	var config EntityConfig
+	if err := json.Unmarshal(climate.Payload, &config); err != nil {
+		t.Fatalf("Failed to unmarshal discovery payload: %v", err)
+	}
+
+	if config.UniqueID != "voron_2_4_extruder" {
+		t.Errorf("Expected unique ID 'voron_2_4_extruder', got '%s'", config.UniqueID)
+	}
+
+	if config.Device.Name != "Voron 2.4" || config.Device.SWVersion != "v0.12.0" {
+		t.Errorf("Unexpected device info: %+v", config.Device)
+	}
+
+	if len(config.Availability) != 2 || config.AvailabilityMode != "all" {
+		t.Errorf("Unexpected availability: %+v, mode %s", config.Availability, config.AvailabilityMode)
+	}
+
+	if config.MinTemp == nil || *config.MinTemp != 0 {
+		t.Errorf("Expected min_temp 0, got %v", config.MinTemp)
+	}
+
+	if !strings.Contains(config.TemperatureCommandTemplate, `"heater": "extruder"`) {
+		t.Errorf("Unexpected temperature command template: %s", config.TemperatureCommandTemplate)
+	}
+}
@@ -0,0 +1,75 @@
+package homeassistant
+
+const (
+	COMPONENT_SENSOR  = "sensor"
+	COMPONENT_BUTTON  = "button"
+	COMPONENT_CLIMATE = "climate"
+	STATUS_ONLINE     = "online"
+)
+
+type Device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+type Availability struct {
+	Topic string `json:"topic"`
+}
+
+type EntityConfig struct {
+	Name                       string         `json:"name"`
+	UniqueID                   string         `json:"unique_id"`
+	ObjectID                   string         `json:"object_id,omitempty"`
+	Icon                       string         `json:"icon,omitempty"`
+	DeviceClass                string         `json:"device_class,omitempty"`
+	StateClass                 string         `json:"state_class,omitempty"`
+	UnitOfMeasurement          string         `json:"unit_of_measurement,omitempty"`
+	StateTopic                 string         `json:"state_topic,omitempty"`
+	ValueTemplate              string         `json:"value_template,omitempty"`
+	CommandTopic               string         `json:"command_topic,omitempty"`
+	PayloadPress               string         `json:"payload_press,omitempty"`
+	CurrentTemperatureTopic    string         `json:"current_temperature_topic,omitempty"`
+	CurrentTemperatureTemplate string         `json:"current_temperature_template,omitempty"`
+	TemperatureStateTopic      string         `json:"temperature_state_topic,omitempty"`
+	TemperatureStateTemplate   string         `json:"temperature_state_template,omitempty"`
+	TemperatureCommandTopic    string         `json:"temperature_command_topic,omitempty"`
+	TemperatureCommandTemplate string         `json:"temperature_command_template,omitempty"`
+	TemperatureUnit            string         `json:"temperature_unit,omitempty"`
+	Modes                      []string       `json:"modes,omitempty"`
+	MinTemp                    *float64       `json:"min_temp,omitempty"`
+	MaxTemp                    float64        `json:"max_temp,omitempty"`
+	TempStep                   float64        `json:"temp_step,omitempty"`
+	Precision                  float64        `json:"precision,omitempty"`
+	Availability               []Availability `json:"availability,omitempty"`
+	AvailabilityMode           string         `json:"availability_mode,omitempty"`
+	Device                     Device         `json:"device"`
+}
+
+type Entity struct {
+	Component string
+	ObjectID  string
+	Config    EntityConfig
+}
+
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+type Discovery struct {
+	discoveryPrefix    string
+	nodeID             string
+	topicPrefix        string
+	commandsEnabled    bool
+	availabilityTopics []string
+}
+
+type PrinterDescription struct {
+	Hostname         string
+	SoftwareVersion  string
+	AvailableObjects []string
+	MonitoredObjects map[string]any
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func mergeFields(current []Field, keyValues []any) []Field {
+	fields := make([]Field, len(current), len(current)+len(keyValues)/2)
+	copy(fields, current)
+
+	for i := 0; i < len(keyValues); i += 2 {
+		key, ok := keyValues[i].(string)
+		if !ok || key == "" {
+			key = BAD_KEY
+		}
+
+		var value any
+		if i+1 < len(keyValues) {
+			value = keyValues[i+1]
+		} else {
+			value, key = keyValues[i], BAD_KEY
+		}
+
+		replaced := false
+		for j := range fields {
+			if fields[j].Key == key {
+				fields[j].Value = value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			fields = append(fields, Field{Key: key, Value: value})
+		}
+	}
+
+	return fields
+}
+
+func formatText(entry Entry) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "[%s] %s: ", entry.Level.String(), entry.Time.Format(TIMESTAMP_FORMAT))
+	if entry.Component != "" {
+		fmt.Fprintf(&builder, "[%s] ", entry.Component)
+	}
+	builder.WriteString(entry.Message)
+
+	for _, field := range entry.Fields {
+		builder.WriteByte(' ')
+		builder.WriteString(field.Key)
+		builder.WriteByte('=')
+		builder.WriteString(textValue(field.Value))
+	}
+
+	return builder.String()
+}
+
+func formatJSON(entry Entry) string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONField(&buf, "timestamp", entry.Time.UTC().Format(time.RFC3339Nano), false)
+	writeJSONField(&buf, "level", strings.ToLower(entry.Level.String()), true)
+	if entry.Component != "" {
+		writeJSONField(&buf, "component", entry.Component, true)
+	}
+	writeJSONField(&buf, "message", entry.Message, true)
+
+	for _, field := range entry.Fields {
+		switch field.Key {
+		case "timestamp", "level", "component", "message":
+			writeJSONField(&buf, "fields."+field.Key, field.Value, true)
+		default:
+			writeJSONField(&buf, field.Key, field.Value, true)
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value any, separator bool) {
+	if separator {
+		buf.WriteByte(',')
+	}
+
+	encodedKey, _ := json.Marshal(key)
+	buf.Write(encodedKey)
+	buf.WriteByte(':')
+	buf.Write(jsonValue(value))
+}
+
+func jsonValue(value any) []byte {
+	switch v := value.(type) {
+	case error:
+		value = v.Error()
+	case fmt.Stringer:
+		value = v.String()
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	return data
+}
+
+func textValue(value any) string {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case error:
+		text = v.Error()
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		return strconv.Quote(text)
+	}
+	return text
+}
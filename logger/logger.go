@@ -1,24 +1,14 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"moonraker2mqtt/config"
-	"moonraker2mqtt/utils"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
-)
-
-type LogLevel int
 
-const (
-	DEBUG LogLevel = iota
-	INFO
-	WARN
-	ERROR
+	"moonraker2mqtt/config"
 )
 
 func (l LogLevel) String() string {
@@ -51,64 +41,109 @@ func ParseLogLevel(s string) LogLevel {
 	}
 }
 
-type Logger interface {
-	Debug(format string, args ...any)
-	Info(format string, args ...any)
-	Warn(format string, args ...any)
-	Error(format string, args ...any)
-	SetLevel(level LogLevel)
-	GetLevel() LogLevel
-}
+func New(cfg *config.LoggingConfig) (Logger, error) {
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []config.LogOutputConfig{{Type: config.LOG_OUTPUT_STDOUT}}
+	}
 
-type logger struct {
-	level  LogLevel
-	output io.Writer
-	std    *log.Logger
-	cfg    *config.LoggingConfig
+	sinks := make([]sink, 0, len(outputs))
+	for _, output := range outputs {
+		s, err := newSink(output)
+		if err != nil {
+			for _, opened := range sinks {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to open %s log output: %w", output.Type, err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	return newLogger(cfg, sinks), nil
 }
 
-func New(cfg *config.LoggingConfig, environment string) Logger {
-	rootPath := utils.GetRootPath()
-	logFile := filepath.Join(rootPath, "logs", "moonraker2mqtt.log")
+func NewWithWriter(cfg *config.LoggingConfig, output io.Writer) Logger {
+	return newLogger(cfg, []sink{&writerSink{writer: output}})
+}
 
-	err := utils.MkdirIfNotExists(logFile)
-	if err != nil {
-		fmt.Printf("Failed to create logs directory: %v\n", err)
-		return nil
-	}
-	var output io.Writer
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		fmt.Printf("Failed to open log file: %v\n", err)
-		return nil
+func newLogger(cfg *config.LoggingConfig, sinks []sink) *logger {
+	return &logger{
+		core: &core{
+			level:  ParseLogLevel(cfg.Level),
+			levels: componentLevels(cfg.Components),
+			format: strings.ToLower(cfg.Format),
+			sinks:  sinks,
+		},
 	}
+}
 
-	if environment == "development" {
-		output = io.MultiWriter(os.Stdout, file)
-	} else {
-		output = file
+func Reconfigure(l Logger, cfg *config.LoggingConfig) {
+	root, ok := l.(*logger)
+	if !ok {
+		return
 	}
 
-	return &logger{
-		level:  ParseLogLevel(cfg.Level),
-		output: output,
-		std:    log.New(output, "", 0),
-		cfg:    cfg,
+	root.core.mux.Lock()
+	defer root.core.mux.Unlock()
+
+	root.core.level = ParseLogLevel(cfg.Level)
+	root.core.levels = componentLevels(cfg.Components)
+}
+
+func componentLevels(components map[string]string) map[string]LogLevel {
+	levels := make(map[string]LogLevel, len(components))
+	for component, level := range components {
+		levels[component] = ParseLogLevel(level)
 	}
+	return levels
 }
 
-func (l *logger) formatMessage(level LogLevel, format string, args ...any) string {
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	message := fmt.Sprintf(format, args...)
-	return fmt.Sprintf("[%s] %s: %s", level.String(), timestamp, message)
+func Close(l Logger) error {
+	root, ok := l.(*logger)
+	if !ok {
+		return nil
+	}
+
+	root.core.writeMux.Lock()
+	defer root.core.writeMux.Unlock()
+
+	var errs []error
+	for _, s := range root.core.sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func (l *logger) log(level LogLevel, format string, args ...any) {
-	if level < l.level {
+	if level < l.GetLevel() {
 		return
 	}
-	message := l.formatMessage(level, format, args...)
-	l.std.Println(message)
+
+	entry := Entry{
+		Time:      time.Now(),
+		Level:     level,
+		Component: l.component,
+		Message:   fmt.Sprintf(format, args...),
+		Fields:    l.fields,
+	}
+
+	var line string
+	if l.core.format == FORMAT_JSON {
+		line = formatJSON(entry)
+	} else {
+		line = formatText(entry)
+	}
+
+	l.core.writeMux.Lock()
+	defer l.core.writeMux.Unlock()
+
+	for _, s := range l.core.sinks {
+		if err := s.Write(level, line); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write log entry: %v\n", err)
+		}
+	}
 }
 
 func (l *logger) Debug(format string, args ...any) {
@@ -128,9 +163,38 @@ func (l *logger) Error(format string, args ...any) {
 }
 
 func (l *logger) SetLevel(level LogLevel) {
-	l.level = level
+	l.core.mux.Lock()
+	defer l.core.mux.Unlock()
+
+	if l.component != "" {
+		l.core.levels[l.component] = level
+		return
+	}
+	l.core.level = level
 }
 
 func (l *logger) GetLevel() LogLevel {
-	return l.level
+	l.core.mux.RLock()
+	defer l.core.mux.RUnlock()
+
+	if level, exists := l.core.levels[l.component]; exists && l.component != "" {
+		return level
+	}
+	return l.core.level
+}
+
+func (l *logger) With(fields ...any) Logger {
+	return &logger{
+		core:      l.core,
+		component: l.component,
+		fields:    mergeFields(l.fields, fields),
+	}
+}
+
+func (l *logger) WithComponent(component string) Logger {
+	return &logger{
+		core:      l.core,
+		component: component,
+		fields:    l.fields,
+	}
 }
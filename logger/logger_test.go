@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func TestLogger_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&config.LoggingConfig{Level: "debug", Format: "json"}, &buf)
+
+	log.WithComponent("websocket").With("printer", "voron", "attempt", 2).Warn("Connection lost: %v", errors.New("EOF"))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line %q is not valid JSON: %v", buf.String(), err)
+	}
+
+	want := map[string]any{
+		"level":     "warn",
+		"component": "websocket",
+		"message":   "Connection lost: EOF",
+		"printer":   "voron",
+		"attempt":   float64(2),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("entry[%s] = %v, want %v", key, entry[key], value)
+		}
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, entry["timestamp"].(string)); err != nil {
+		t.Errorf("entry timestamp %v is not RFC3339: %v", entry["timestamp"], err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"timestamp":`) {
+		t.Errorf("log line %q should start with the timestamp", buf.String())
+	}
+}
+
+func TestLogger_TextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&config.LoggingConfig{Level: "info", Format: "text"}, &buf)
+
+	log.WithComponent("mqtt").With("topic", "moonraker/state", "payload", "ws connected").Info("Published")
+
+	line := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(line, "[INFO] ") {
+		t.Errorf("log line %q should start with the level", line)
+	}
+	if !strings.HasSuffix(line, `: [mqtt] Published topic=moonraker/state payload="ws connected"`) {
+		t.Errorf("log line %q is missing the component or fields", line)
+	}
+}
+
+func TestLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&config.LoggingConfig{Level: "warn", Format: "text"}, &buf)
+	child := log.WithComponent("bridge")
+
+	child.Info("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("Info() below the configured level wrote %q", buf.String())
+	}
+
+	log.SetLevel(DEBUG)
+	child.Debug("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("Debug() after SetLevel(DEBUG) on the parent wrote %q", buf.String())
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   []Field
+		keyValues []any
+		want      []Field
+	}{
+		{
+			name:      "append",
+			keyValues: []any{"printer", "voron", "topic", "a/b"},
+			want:      []Field{{"printer", "voron"}, {"topic", "a/b"}},
+		},
+		{
+			name:      "override existing key",
+			current:   []Field{{"printer", "voron"}},
+			keyValues: []any{"printer", "ender"},
+			want:      []Field{{"printer", "ender"}},
+		},
+		{
+			name:      "missing value",
+			keyValues: []any{"orphan"},
+			want:      []Field{{BAD_KEY, "orphan"}},
+		},
+		{
+			name:      "non-string key",
+			keyValues: []any{42, "value"},
+			want:      []Field{{BAD_KEY, "value"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeFields(tt.current, tt.keyValues)
+			if len(got) != len(tt.want) {
+				t.Fatalf("mergeFields() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("mergeFields()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeFields_DoesNotShareParentFields(t *testing.T) {
+	parent := mergeFields(nil, []any{"printer", "voron"})
+	child := mergeFields(parent, []any{"printer", "ender"})
+
+	if parent[0].Value != "voron" {
+		t.Errorf("parent field changed to %v after deriving a child", parent[0].Value)
+	}
+	if child[0].Value != "ender" {
+		t.Errorf("child field = %v, want ender", child[0].Value)
+	}
+}
+
+func TestLogger_ComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&config.LoggingConfig{
+		Level:      "info",
+		Format:     "text",
+		Components: map[string]string{"websocket": "debug", "mqtt": "error"},
+	}, &buf)
+
+	log.WithComponent("websocket").Debug("websocket debug")
+	log.WithComponent("mqtt").Warn("mqtt warning")
+	log.WithComponent("bridge").Debug("bridge debug")
+	log.WithComponent("bridge").Info("bridge info")
+
+	output := buf.String()
+	for _, line := range []string{"websocket debug", "bridge info"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("output is missing %q\n%s", line, output)
+		}
+	}
+	for _, line := range []string{"mqtt warning", "bridge debug"} {
+		if strings.Contains(output, line) {
+			t.Errorf("output contains %q filtered by its level\n%s", line, output)
+		}
+	}
+
+	if got := log.WithComponent("mqtt").GetLevel(); got != ERROR {
+		t.Errorf("GetLevel() for mqtt = %v, want ERROR", got)
+	}
+	if got := log.WithComponent("bridge").GetLevel(); got != INFO {
+		t.Errorf("GetLevel() for bridge = %v, want the root level INFO", got)
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithWriter(&config.LoggingConfig{
+		Level:      "info",
+		Format:     "text",
+		Components: map[string]string{"websocket": "debug"},
+	}, &buf)
+
+	Reconfigure(log, &config.LoggingConfig{Level: "warn", Components: map[string]string{"mqtt": "debug"}})
+
+	tests := []struct {
+		component string
+		want      LogLevel
+	}{
+		{"", WARN},
+		{"websocket", WARN},
+		{"mqtt", DEBUG},
+	}
+
+	for _, tt := range tests {
+		if got := log.WithComponent(tt.component).GetLevel(); got != tt.want {
+			t.Errorf("GetLevel() for %q = %v, want %v", tt.component, got, tt.want)
+		}
+	}
+}
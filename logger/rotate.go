@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func openRotatingFile(cfg config.LogOutputConfig) (*rotatingFile, error) {
+	path, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log file path '%s': %w", cfg.Path, err)
+	}
+
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    int64(cfg.MaxSize) * BYTES_PER_MEGABYTE,
+		interval:   time.Duration(cfg.RotateInterval) * time.Hour,
+		maxBackups: cfg.MaxBackups,
+		maxAge:     time.Duration(cfg.MaxAge) * 24 * time.Hour,
+		now:        time.Now,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Close() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = f.now()
+	return nil
+}
+
+func (f *rotatingFile) shouldRotate(incoming int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+incoming > f.maxSize {
+		return true
+	}
+	return f.interval > 0 && f.now().Sub(f.opened) >= f.interval
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	if err := os.Rename(f.path, f.backupName(f.now())); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.removeExpiredBackups()
+	return nil
+}
+
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), t.UTC().Format(BACKUP_TIME_FORMAT), ext)
+}
+
+func (f *rotatingFile) backups() []logBackup {
+	ext := filepath.Ext(f.path)
+	prefix := filepath.Base(strings.TrimSuffix(f.path, ext)) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil
+	}
+
+	var backups []logBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		timestamp, err := time.Parse(BACKUP_TIME_FORMAT, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, logBackup{path: filepath.Join(filepath.Dir(f.path), name), time: timestamp})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups
+}
+
+func (f *rotatingFile) removeExpiredBackups() {
+	if f.maxBackups == 0 && f.maxAge == 0 {
+		return
+	}
+
+	cutoff := f.now().Add(-f.maxAge)
+	for i, backup := range f.backups() {
+		expired := f.maxBackups > 0 && i >= f.maxBackups
+		expired = expired || (f.maxAge > 0 && backup.time.Before(cutoff))
+		if expired {
+			os.Remove(backup.path)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func newTestRotatingFile(t *testing.T, cfg config.LogOutputConfig, now *time.Time) *rotatingFile {
+	t.Helper()
+
+	cfg.Type = config.LOG_OUTPUT_FILE
+	cfg.Path = filepath.Join(t.TempDir(), "nested", "bridge.log")
+
+	file, err := openRotatingFile(cfg)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	file.now = func() time.Time { return *now }
+	file.opened = *now
+	return file
+}
+
+func TestRotatingFile_RotatesOnSize(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	file := newTestRotatingFile(t, config.LogOutputConfig{}, &now)
+	file.maxSize = 10
+
+	file.Write([]byte("0123456789"))
+	now = now.Add(time.Second)
+	file.Write([]byte("abc"))
+
+	data, _ := os.ReadFile(file.path)
+	if string(data) != "abc" {
+		t.Errorf("active log = %q, want only the entry written after rotation", data)
+	}
+
+	backups := file.backups()
+	if len(backups) != 1 {
+		t.Fatalf("backups() = %v, want one rotated file", backups)
+	}
+	if !strings.HasSuffix(backups[0].path, "bridge-2024-05-01T12-00-01.000.log") {
+		t.Errorf("backup path = %s, want a timestamped name next to the log", backups[0].path)
+	}
+	data, _ = os.ReadFile(backups[0].path)
+	if string(data) != "0123456789" {
+		t.Errorf("rotated log = %q, want the entries written before rotation", data)
+	}
+}
+
+func TestRotatingFile_RotatesOnInterval(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	file := newTestRotatingFile(t, config.LogOutputConfig{RotateInterval: 24}, &now)
+
+	file.Write([]byte("day one\n"))
+	now = now.Add(23 * time.Hour)
+	file.Write([]byte("still day one\n"))
+	if len(file.backups()) != 0 {
+		t.Fatal("rotated before the interval elapsed")
+	}
+
+	now = now.Add(time.Hour)
+	file.Write([]byte("day two\n"))
+	if len(file.backups()) != 1 {
+		t.Errorf("backups() = %v, want one rotation after the interval", file.backups())
+	}
+}
+
+func TestRotatingFile_Retention(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	file := newTestRotatingFile(t, config.LogOutputConfig{MaxBackups: 2, MaxAge: 1}, &now)
+	file.maxSize = 1
+
+	for i := 0; i < 4; i++ {
+		file.Write([]byte("entry"))
+		now = now.Add(time.Hour)
+	}
+
+	backups := file.backups()
+	if len(backups) != 2 {
+		t.Fatalf("backups() = %v, want the 2 most recent rotations", backups)
+	}
+	if !backups[0].time.After(backups[1].time) {
+		t.Errorf("backups() = %v, want newest first", backups)
+	}
+
+	now = now.Add(48 * time.Hour)
+	file.Write([]byte("entry"))
+
+	backups = file.backups()
+	if len(backups) != 1 {
+		t.Errorf("backups() = %v, want only the rotation made after max_age", backups)
+	}
+}
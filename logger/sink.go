@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func newSink(cfg config.LogOutputConfig) (sink, error) {
+	switch cfg.Type {
+	case config.LOG_OUTPUT_STDOUT:
+		return &writerSink{writer: os.Stdout}, nil
+	case config.LOG_OUTPUT_STDERR:
+		return &writerSink{writer: os.Stderr}, nil
+	case config.LOG_OUTPUT_JOURNALD:
+		return &journaldSink{writer: os.Stderr}, nil
+	case config.LOG_OUTPUT_SYSLOG:
+		return newSyslogSink(cfg.Path, cfg.Tag)
+	case config.LOG_OUTPUT_FILE:
+		file, err := openRotatingFile(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &writerSink{writer: file}, nil
+	default:
+		return nil, fmt.Errorf("unknown log output type '%s'", cfg.Type)
+	}
+}
+
+func (s *writerSink) Write(level LogLevel, line string) error {
+	_, err := io.WriteString(s.writer, line+"\n")
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if closer, ok := s.writer.(io.Closer); ok && s.writer != os.Stdout && s.writer != os.Stderr {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *journaldSink) Write(level LogLevel, line string) error {
+	_, err := fmt.Fprintf(s.writer, "<%d>%s\n", syslogSeverity(level), line)
+	return err
+}
+
+func (s *journaldSink) Close() error {
+	return nil
+}
+
+func newSyslogSink(address, tag string) (*syslogSink, error) {
+	if tag == "" {
+		tag = DEFAULT_SYSLOG_TAG
+	}
+
+	s := &syslogSink{address: address, tag: tag}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *syslogSink) connect() error {
+	addresses := syslogAddresses
+	if s.address != "" {
+		addresses = []string{s.address}
+	}
+
+	var lastErr error
+	for _, address := range addresses {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, address)
+			if err == nil {
+				s.conn = conn
+				return nil
+			}
+			lastErr = err
+		}
+	}
+
+	return fmt.Errorf("failed to connect to syslog: %w", lastErr)
+}
+
+func (s *syslogSink) Write(level LogLevel, line string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	message := fmt.Sprintf("<%d>%s %s[%d]: %s\n",
+		SYSLOG_FACILITY_DAEMON*8+syslogSeverity(level),
+		time.Now().Format(time.Stamp),
+		s.tag,
+		os.Getpid(),
+		line,
+	)
+
+	if s.conn != nil {
+		if _, err := io.WriteString(s.conn, message); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(s.conn, message)
+	return err
+}
+
+func (s *syslogSink) Close() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func syslogSeverity(level LogLevel) int {
+	switch level {
+	case DEBUG:
+		return SYSLOG_SEVERITY_DEBUG
+	case WARN:
+		return SYSLOG_SEVERITY_WARNING
+	case ERROR:
+		return SYSLOG_SEVERITY_ERROR
+	default:
+		return SYSLOG_SEVERITY_INFO
+	}
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+)
+
+func TestJournaldSink_Write(t *testing.T) {
+	tests := []struct {
+		level LogLevel
+		want  string
+	}{
+		{DEBUG, "<7>message\n"},
+		{INFO, "<6>message\n"},
+		{WARN, "<4>message\n"},
+		{ERROR, "<3>message\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			sink := &journaldSink{writer: &buf}
+
+			if err := sink.Write(tt.level, "message"); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogSink_Write(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets are not available: %v", err)
+	}
+	defer listener.Close()
+
+	sink, err := newSyslogSink(socketPath, "")
+	if err != nil {
+		t.Fatalf("newSyslogSink() error = %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Write(WARN, "printer offline"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to read syslog message: %v", err)
+	}
+
+	message := string(buf[:n])
+	if !strings.HasPrefix(message, "<28>") {
+		t.Errorf("syslog message %q should carry the daemon.warning priority <28>", message)
+	}
+	if !strings.Contains(message, "moonraker2mqtt[") || !strings.HasSuffix(message, "]: printer offline\n") {
+		t.Errorf("syslog message %q is missing the tag or message", message)
+	}
+}
+
+func TestNew_FileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "bridge.log")
+
+	log, err := New(&config.LoggingConfig{
+		Level:   "info",
+		Format:  "text",
+		Outputs: []config.LogOutputConfig{{Type: config.LOG_OUTPUT_FILE, Path: path}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.Info("written to file")
+	if err := Close(log); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "written to file") {
+		t.Errorf("log file = %q, want the logged message", data)
+	}
+}
+
+func TestNew_InvalidOutput(t *testing.T) {
+	_, err := New(&config.LoggingConfig{
+		Level:   "info",
+		Format:  "text",
+		Outputs: []config.LogOutputConfig{{Type: config.LOG_OUTPUT_SYSLOG, Path: filepath.Join(t.TempDir(), "missing.sock")}},
+	})
+	if err == nil {
+		t.Error("New() with an unreachable syslog socket should fail")
+	}
+}
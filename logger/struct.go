@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type LogLevel int
+
+const (
+	DEBUG LogLevel = iota
+	INFO
+	WARN
+	ERROR
+)
+
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+
+	TIMESTAMP_FORMAT   = "2006-01-02 15:04:05.000"
+	BACKUP_TIME_FORMAT = "2006-01-02T15-04-05.000"
+	BAD_KEY            = "!BADKEY"
+
+	BYTES_PER_MEGABYTE = 1024 * 1024
+
+	DEFAULT_SYSLOG_TAG      = "moonraker2mqtt"
+	SYSLOG_FACILITY_DAEMON  = 3
+	SYSLOG_SEVERITY_ERROR   = 3
+	SYSLOG_SEVERITY_WARNING = 4
+	SYSLOG_SEVERITY_INFO    = 6
+	SYSLOG_SEVERITY_DEBUG   = 7
+)
+
+var syslogAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+type Logger interface {
+	Debug(format string, args ...any)
+	Info(format string, args ...any)
+	Warn(format string, args ...any)
+	Error(format string, args ...any)
+	SetLevel(level LogLevel)
+	GetLevel() LogLevel
+	With(fields ...any) Logger
+	WithComponent(component string) Logger
+}
+
+type logger struct {
+	core      *core
+	component string
+	fields    []Field
+}
+
+type core struct {
+	level    LogLevel
+	levels   map[string]LogLevel
+	format   string
+	sinks    []sink
+	mux      sync.RWMutex
+	writeMux sync.Mutex
+}
+
+type Field struct {
+	Key   string
+	Value any
+}
+
+type Entry struct {
+	Time      time.Time
+	Level     LogLevel
+	Component string
+	Message   string
+	Fields    []Field
+}
+
+type sink interface {
+	Write(level LogLevel, line string) error
+	Close() error
+}
+
+type writerSink struct {
+	writer io.Writer
+}
+
+type journaldSink struct {
+	writer io.Writer
+}
+
+type syslogSink struct {
+	address string
+	tag     string
+	conn    net.Conn
+	mux     sync.Mutex
+}
+
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	maxAge     time.Duration
+	file       *os.File
+	size       int64
+	opened     time.Time
+	now        func() time.Time
+	mux        sync.Mutex
+}
+
+type logBackup struct {
+	path string
+	time time.Time
+}
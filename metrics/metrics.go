@@ -0,0 +1,308 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	DefaultRegistry = NewRegistry()
+
+	MQTTPublishes = DefaultRegistry.NewCounter(
+		"moonraker2mqtt_mqtt_publishes_total",
+		"Messages published to the MQTT broker.",
+	)
+	MQTTPublishFailures = DefaultRegistry.NewCounter(
+		"moonraker2mqtt_mqtt_publish_failures_total",
+		"Messages that could not be published to the MQTT broker.",
+	)
+	RPCCalls = DefaultRegistry.NewCounter(
+		"moonraker2mqtt_rpc_calls_total",
+		"JSON-RPC calls made to Moonraker.",
+		"method", "result",
+	)
+	RPCDuration = DefaultRegistry.NewHistogram(
+		"moonraker2mqtt_rpc_duration_seconds",
+		"Latency of JSON-RPC calls made to Moonraker.",
+		DefaultBuckets,
+		"method",
+	)
+	Reconnects = DefaultRegistry.NewCounter(
+		"moonraker2mqtt_reconnects_total",
+		"Reconnection attempts per link.",
+		"link", "result",
+	)
+	Commands = DefaultRegistry.NewCounter(
+		"moonraker2mqtt_commands_total",
+		"Commands executed from MQTT.",
+		"command", "status",
+	)
+)
+
+type textWriter struct {
+	*bufio.Writer
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) Register(collector Collector) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.collectors = append(r.collectors, collector)
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	counter := &Counter{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*counterValue),
+	}
+	r.Register(counter)
+	return counter
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	histogram := &Histogram{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+	r.Register(histogram)
+	return histogram
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, collect func() []GaugeValue, labels ...string) *GaugeFunc {
+	gauge := &GaugeFunc{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		collect: collect,
+	}
+	r.Register(gauge)
+	return gauge
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	collectors := r.collectors[:0]
+	for _, collector := range r.collectors {
+		if collector.Name() != name {
+			collectors = append(collectors, collector)
+		}
+	}
+	r.collectors = collectors
+}
+
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mux.RLock()
+	collectors := make([]Collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mux.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+
+	writer := &textWriter{bufio.NewWriter(w)}
+	for _, collector := range collectors {
+		collector.Write(writer)
+	}
+	return writer.Flush()
+}
+
+func (c *Counter) Name() string {
+	return c.name
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	value, exists := c.values[key]
+	if !exists {
+		value = &counterValue{labels: append([]string(nil), labelValues...)}
+		c.values[key] = value
+	}
+	value.value += delta
+}
+
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if value, exists := c.values[labelKey(labelValues)]; exists {
+		return value.value
+	}
+	return 0
+}
+
+func (c *Counter) Write(w *textWriter) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	w.header(c.name, c.help, METRIC_TYPE_COUNTER)
+	if len(c.values) == 0 && len(c.labels) == 0 {
+		w.sample(c.name, nil, nil, 0)
+		return
+	}
+
+	for _, key := range sortedKeys(c.values) {
+		value := c.values[key]
+		w.sample(c.name, c.labels, value.labels, value.value)
+	}
+}
+
+func (h *Histogram) Name() string {
+	return h.name
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := labelKey(labelValues)
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	entry, exists := h.values[key]
+	if !exists {
+		entry = &histogramValue{
+			labels: append([]string(nil), labelValues...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = entry
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			entry.counts[i]++
+		}
+	}
+	entry.count++
+	entry.sum += value
+}
+
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if entry, exists := h.values[labelKey(labelValues)]; exists {
+		return entry.count
+	}
+	return 0
+}
+
+func (h *Histogram) Write(w *textWriter) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	w.header(h.name, h.help, METRIC_TYPE_HISTOGRAM)
+
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, key := range sortedKeys(h.values) {
+		entry := h.values[key]
+
+		for i, bound := range h.buckets {
+			w.sample(h.name+"_bucket", bucketLabels, append(append([]string(nil), entry.labels...), formatFloat(bound)), float64(entry.counts[i]))
+		}
+		w.sample(h.name+"_bucket", bucketLabels, append(append([]string(nil), entry.labels...), "+Inf"), float64(entry.count))
+		w.sample(h.name+"_sum", h.labels, entry.labels, entry.sum)
+		w.sample(h.name+"_count", h.labels, entry.labels, float64(entry.count))
+	}
+}
+
+func (g *GaugeFunc) Name() string {
+	return g.name
+}
+
+func (g *GaugeFunc) Write(w *textWriter) {
+	values := g.collect()
+	if len(values) == 0 {
+		return
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return labelKey(values[i].LabelValues) < labelKey(values[j].LabelValues)
+	})
+
+	w.header(g.name, g.help, METRIC_TYPE_GAUGE)
+	for _, value := range values {
+		w.sample(g.name, g.labels, value.LabelValues, value.Value)
+	}
+}
+
+func (w *textWriter) header(name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+func (w *textWriter) sample(name string, labels, values []string, value float64) {
+	w.WriteString(name)
+
+	written := 0
+	for i, label := range labels {
+		if i >= len(values) || values[i] == "" {
+			continue
+		}
+		if written == 0 {
+			w.WriteByte('{')
+		} else {
+			w.WriteByte(',')
+		}
+		fmt.Fprintf(w, "%s=\"%s\"", label, escapeLabelValue(values[i]))
+		written++
+	}
+	if written > 0 {
+		w.WriteByte('}')
+	}
+	fmt.Fprintf(w, " %s\n", formatFloat(value))
+}
+
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeHelp(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(value)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
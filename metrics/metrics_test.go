@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	registry := NewRegistry()
+
+	publishes := registry.NewCounter("test_publishes_total", "Published messages.")
+	calls := registry.NewCounter("test_calls_total", "Calls.", "method", "result")
+	duration := registry.NewHistogram("test_duration_seconds", "Latency.", []float64{0.1, 1}, "method")
+
+	publishes.Inc()
+	publishes.Add(2)
+	calls.Inc("printer.info", RESULT_SUCCESS)
+	calls.Inc("printer.info", RESULT_SUCCESS)
+	calls.Inc("printer.gcode.script", RESULT_ERROR)
+	duration.Observe(0.05, "printer.info")
+	duration.Observe(0.5, "printer.info")
+	duration.Observe(3, "printer.info")
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP test_calls_total Calls.
+# TYPE test_calls_total counter
+test_calls_total{method="printer.gcode.script",result="error"} 1
+test_calls_total{method="printer.info",result="success"} 2
+# HELP test_duration_seconds Latency.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="printer.info",le="0.1"} 1
+test_duration_seconds_bucket{method="printer.info",le="1"} 2
+test_duration_seconds_bucket{method="printer.info",le="+Inf"} 3
+test_duration_seconds_sum{method="printer.info"} 3.55
+test_duration_seconds_count{method="printer.info"} 3
+# HELP test_publishes_total Published messages.
+# TYPE test_publishes_total counter
+test_publishes_total 3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistry_WriteText_UnusedCounter(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "Unused.")
+	registry.NewCounter("test_labeled_total", "Unused with labels.", "link")
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "test_total 0\n") {
+		t.Errorf("WriteText() = %s, want an unlabeled counter to report 0", buf.String())
+	}
+	if strings.Contains(buf.String(), "test_labeled_total{") {
+		t.Errorf("WriteText() = %s, want no samples for an unused labeled counter", buf.String())
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{`quote"d`, `quote\"d`},
+		{`back\slash`, `back\\slash`},
+		{"new\nline", `new\nline`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLabelValue(tt.value); got != tt.want {
+			t.Errorf("escapeLabelValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
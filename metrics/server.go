@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+)
+
+const (
+	CONTENT_TYPE_TEXT   = "text/plain; version=0.0.4; charset=utf-8"
+	CONTENT_TYPE_JSON   = "application/json"
+	READ_HEADER_TIMEOUT = 5 * time.Second
+	SHUTDOWN_TIMEOUT    = 5 * time.Second
+)
+
+func NewServer(config *config.HTTPConfig, registry *Registry, readiness ReadinessFunc, logger logger.Logger) *Server {
+	s := &Server{
+		config:    config,
+		registry:  registry,
+		readiness: readiness,
+		logger:    logger.WithComponent(LOG_COMPONENT),
+	}
+
+	s.server = &http.Server{
+		Addr:              config.GetAddress(),
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: READ_HEADER_TIMEOUT,
+	}
+
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Serving health and metrics on http://%s", listener.Addr())
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("HTTP server stopped: %v", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Warn("Failed to shut down HTTP server: %v", err)
+	}
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", CONTENT_TYPE_TEXT)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	response := readinessResponse{Ready: true, Checks: map[string]string{}}
+	if s.readiness != nil {
+		response.Ready, response.Checks = s.readiness()
+	}
+
+	status := http.StatusOK
+	if !response.Ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", CONTENT_TYPE_TEXT)
+	if err := s.registry.WriteText(w); err != nil {
+		s.logger.Warn("Failed to write metrics: %v", err)
+	}
+}
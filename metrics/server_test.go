@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+)
+
+type testLogger struct{}
+
+func (testLogger) Debug(format string, args ...any)               {}
+func (testLogger) Info(format string, args ...any)                {}
+func (testLogger) Warn(format string, args ...any)                {}
+func (testLogger) Error(format string, args ...any)               {}
+func (testLogger) SetLevel(level logger.LogLevel)                 {}
+func (testLogger) GetLevel() logger.LogLevel                      { return logger.DEBUG }
+func (l testLogger) With(fields ...any) logger.Logger             { return l }
+func (l testLogger) WithComponent(component string) logger.Logger { return l }
+
+func TestServer_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "Test counter.").Inc()
+
+	ready := false
+	readiness := func() (bool, map[string]string) {
+		return ready, map[string]string{"mqtt": "connected", "klippy/voron": "startup"}
+	}
+
+	server := NewServer(&config.HTTPConfig{Enabled: true}, registry, readiness, testLogger{})
+	handler := server.Handler()
+
+	tests := []struct {
+		name       string
+		path       string
+		ready      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"liveness", "/healthz", false, http.StatusOK, "ok"},
+		{"not ready", "/readyz", false, http.StatusServiceUnavailable, `"klippy/voron":"startup"`},
+		{"ready", "/readyz", true, http.StatusOK, `"ready":true`},
+		{"metrics", "/metrics", false, http.StatusOK, "test_total 1"},
+		{"unknown path", "/unknown", false, http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready = tt.ready
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, recorder.Code, tt.wantStatus)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s body = %s, expected to contain %s", tt.path, recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestServer_ReadyzPayload(t *testing.T) {
+	server := NewServer(&config.HTTPConfig{Enabled: true}, NewRegistry(), func() (bool, map[string]string) {
+		return true, map[string]string{"mqtt": "connected"}
+	}, testLogger{})
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var response readinessResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode readiness response: %v", err)
+	}
+	if !response.Ready || response.Checks["mqtt"] != "connected" {
+		t.Errorf("readiness response = %+v, want ready with mqtt connected", response)
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+)
+
+const (
+	METRIC_TYPE_COUNTER   = "counter"
+	METRIC_TYPE_GAUGE     = "gauge"
+	METRIC_TYPE_HISTOGRAM = "histogram"
+
+	LOG_COMPONENT = "http"
+
+	RESULT_SUCCESS = "success"
+	RESULT_ERROR   = "error"
+)
+
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type Collector interface {
+	Name() string
+	Write(w *textWriter)
+}
+
+type ReadinessFunc func() (bool, map[string]string)
+
+type Registry struct {
+	collectors []Collector
+	mux        sync.RWMutex
+}
+
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+	values map[string]*counterValue
+	mux    sync.Mutex
+}
+
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+type Histogram struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	values  map[string]*histogramValue
+	mux     sync.Mutex
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type GaugeFunc struct {
+	name    string
+	help    string
+	labels  []string
+	collect func() []GaugeValue
+}
+
+type GaugeValue struct {
+	LabelValues []string
+	Value       float64
+}
+
+type Server struct {
+	config    *config.HTTPConfig
+	registry  *Registry
+	readiness ReadinessFunc
+	server    *http.Server
+	logger    logger.Logger
+}
+
+type readinessResponse struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
@@ -0,0 +1,96 @@
+package moonraker
+
+import (
+	"sort"
+	"sync"
+)
+
+type ObjectCache struct {
+	objects map[string]map[string]any
+	mux     sync.RWMutex
+}
+
+func NewObjectCache() *ObjectCache {
+	return &ObjectCache{
+		objects: make(map[string]map[string]any),
+	}
+}
+
+func (c *ObjectCache) Merge(status map[string]any) []string {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	updated := make([]string, 0, len(status))
+	for objectName, objectData := range status {
+		if objectName == "eventtime" {
+			continue
+		}
+
+		fields, ok := objectData.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		current, exists := c.objects[objectName]
+		if !exists {
+			current = make(map[string]any, len(fields))
+			c.objects[objectName] = current
+		}
+
+		for field, value := range fields {
+			current[field] = value
+		}
+
+		updated = append(updated, objectName)
+	}
+
+	sort.Strings(updated)
+	return updated
+}
+
+func (c *ObjectCache) Get(objectName string) (map[string]any, bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	current, exists := c.objects[objectName]
+	if !exists {
+		return nil, false
+	}
+
+	fields := make(map[string]any, len(current))
+	for field, value := range current {
+		fields[field] = value
+	}
+
+	return fields, true
+}
+
+func (c *ObjectCache) Names() []string {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	names := make([]string, 0, len(c.objects))
+	for objectName := range c.objects {
+		names = append(names, objectName)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (c *ObjectCache) Reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.objects = make(map[string]map[string]any)
+}
+
+func ParseStatusUpdate(params any) (map[string]any, bool) {
+	args, ok := params.([]any)
+	if !ok || len(args) == 0 {
+		return nil, false
+	}
+
+	status, ok := args[0].(map[string]any)
+	return status, ok
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"moonraker2mqtt/config"
 	"moonraker2mqtt/logger"
+	"moonraker2mqtt/metrics"
 	"moonraker2mqtt/websocket"
 )
 
@@ -18,31 +21,45 @@ type Listener interface {
 }
 
 type Client struct {
-	wsClient websocket.Client
-	listener Listener
-	logger   logger.Logger
-}
-
-type CommandMessage struct {
-	Command string                 `json:"command"`
-	Params  map[string]interface{} `json:"params"`
+	wsClient        websocket.Client
+	listener        Listener
+	rpcFilter       func(method string) bool
+	rpcFilterMux    sync.RWMutex
+	consoleCaptures map[*consoleCapture]struct{}
+	consoleMux      sync.Mutex
+	logger          logger.Logger
 }
 
 type clientListener struct {
+	client *Client
 	parent Listener
 }
 
 func NewClient(config *config.MoonrakerConfig, logger logger.Logger, listener Listener) *Client {
+	client := &Client{
+		listener:        listener,
+		consoleCaptures: make(map[*consoleCapture]struct{}),
+		logger:          logger.WithComponent(LOG_COMPONENT),
+	}
 
-	wsClient := websocket.NewWebSocketClient(config, &clientListener{
+	client.wsClient = websocket.NewWebSocketClient(config, &clientListener{
+		client: client,
 		parent: listener,
 	}, logger)
 
-	return &Client{
-		wsClient: wsClient,
-		listener: listener,
-		logger:   logger,
-	}
+	return client
+}
+
+func (c *Client) SetRPCFilter(filter func(method string) bool) {
+	c.rpcFilterMux.Lock()
+	defer c.rpcFilterMux.Unlock()
+	c.rpcFilter = filter
+}
+
+func (c *Client) isRPCMethodAllowed(method string) bool {
+	c.rpcFilterMux.RLock()
+	defer c.rpcFilterMux.RUnlock()
+	return c.rpcFilter != nil && c.rpcFilter(method)
 }
 
 func (c *Client) Connect(ctx context.Context) error {
@@ -53,6 +70,10 @@ func (c *Client) Disconnect() error {
 	return c.wsClient.Disconnect()
 }
 
+func (c *Client) Reconfigure(config *config.MoonrakerConfig) error {
+	return c.wsClient.Reconfigure(config)
+}
+
 func (c *Client) IsConnected() bool {
 	return c.wsClient.IsConnected()
 }
@@ -61,20 +82,20 @@ func (c *Client) GetState() string {
 	return c.wsClient.GetState()
 }
 
+func (c *Client) LastSeen() time.Time {
+	return c.wsClient.LastSeen()
+}
+
 func (c *Client) CallMethod(ctx context.Context, method string, params any) (any, error) {
+	start := time.Now()
 	response, err := c.wsClient.Request(ctx, method, params)
+	metrics.RPCDuration.Observe(time.Since(start).Seconds(), method)
 	if err != nil {
+		metrics.RPCCalls.Inc(method, metrics.RESULT_ERROR)
 		return nil, err
 	}
 
-	if response.IsError() {
-		return nil, &websocket.RPCError{
-			Code:    response.Error.Code,
-			Message: response.Error.Message,
-			Data:    response.Error.Data,
-		}
-	}
-
+	metrics.RPCCalls.Inc(method, metrics.RESULT_SUCCESS)
 	return response.Result, nil
 }
 
@@ -118,6 +139,28 @@ func (c *Client) GetServerInfo(ctx context.Context) (*websocket.ServerInfo, erro
 	return &info, nil
 }
 
+func (c *Client) GetFileMetadata(ctx context.Context, filename string) (*websocket.FileMetadata, error) {
+	result, err := c.CallMethod(ctx, "server.files.metadata", map[string]any{
+		"filename": filename,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata websocket.FileMetadata
+	err = json.Unmarshal(data, &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
 func (c *Client) GetKlippyState(ctx context.Context) (string, error) {
 	info, err := c.GetServerInfo(ctx)
 	if err != nil {
@@ -168,7 +211,35 @@ func (c *Client) QueryObjects(ctx context.Context, objects map[string]any) (map[
 		return nil, websocket.NewWebSocketError("invalid response format", nil)
 	}
 
-	return resultMap, nil
+	status, ok := resultMap["status"].(map[string]any)
+	if !ok {
+		return nil, websocket.NewWebSocketError("invalid status format", nil)
+	}
+
+	return status, nil
+}
+
+func (c *Client) SubscribeObjects(ctx context.Context, objects map[string]any) (map[string]any, error) {
+	params := map[string]any{
+		"objects": objects,
+	}
+
+	result, err := c.CallMethod(ctx, "server.websocket.subscribe", params)
+	if err != nil {
+		return nil, err
+	}
+
+	resultMap, ok := result.(map[string]any)
+	if !ok {
+		return nil, websocket.NewWebSocketError("invalid response format", nil)
+	}
+
+	status, ok := resultMap["status"].(map[string]any)
+	if !ok {
+		return nil, websocket.NewWebSocketError("invalid status format", nil)
+	}
+
+	return status, nil
 }
 
 func (c *Client) RestartPrinter(ctx context.Context) error {
@@ -198,46 +269,68 @@ func (c *Client) ExecuteGcode(ctx context.Context, gcode string) error {
 	return err
 }
 
-func (c *Client) HandleCommand(topic string, payload []byte) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+func (c *Client) PausePrint(ctx context.Context) error {
+	_, err := c.CallMethod(ctx, "printer.print.pause", nil)
+	return err
+}
+
+func (c *Client) ResumePrint(ctx context.Context) error {
+	_, err := c.CallMethod(ctx, "printer.print.resume", nil)
+	return err
+}
 
-	c.logger.Info("Received command on topic: %s", topic)
+func (c *Client) CancelPrint(ctx context.Context) error {
+	_, err := c.CallMethod(ctx, "printer.print.cancel", nil)
+	return err
+}
 
-	var cmdMsg CommandMessage
-	if err := json.Unmarshal(payload, &cmdMsg); err != nil {
-		c.logger.Error("Failed to parse command message: %v", err)
-		return
+func (c *Client) StartPrint(ctx context.Context, filename string) error {
+	params := map[string]any{
+		"filename": filename,
 	}
+	_, err := c.CallMethod(ctx, "printer.print.start", params)
+	return err
+}
+
+func (c *Client) SetHeaterTemperature(ctx context.Context, heater string, target float64) error {
+	return c.ExecuteGcode(ctx, fmt.Sprintf("SET_HEATER_TEMPERATURE HEATER=%s TARGET=%.1f", heater, target))
+}
 
-	if err := c.executeCommand(ctx, cmdMsg.Command, cmdMsg.Params); err != nil {
-		c.logger.Error("Failed to execute command %s: %v", cmdMsg.Command, err)
-	} else {
-		c.logger.Info("Successfully executed command: %s", cmdMsg.Command)
+func (c *Client) SetFanSpeed(ctx context.Context, fan string, speed float64) error {
+	if fan == "" || fan == "fan" {
+		return c.ExecuteGcode(ctx, fmt.Sprintf("M106 S%d", int(speed*255+0.5)))
 	}
+	return c.ExecuteGcode(ctx, fmt.Sprintf("SET_FAN_SPEED FAN=%s SPEED=%.2f", fan, speed))
 }
 
-func (c *Client) executeCommand(ctx context.Context, command string, params map[string]interface{}) error {
-	switch command {
-	case "gcode":
-		return c.handleGcodeCommand(ctx, params)
-	case "emergency_stop":
-		return c.EmergencyStop(ctx)
-	case "restart":
-		return c.RestartPrinter(ctx)
-	case "firmware_restart":
-		return c.RestartFirmware(ctx)
-	default:
-		return fmt.Errorf("unknown command: %s", command)
+func (c *Client) SetSpeedFactor(ctx context.Context, percent float64) error {
+	return c.ExecuteGcode(ctx, fmt.Sprintf("M220 S%.0f", percent))
+}
+
+func (c *Client) SetFlowFactor(ctx context.Context, percent float64) error {
+	return c.ExecuteGcode(ctx, fmt.Sprintf("M221 S%.0f", percent))
+}
+
+func (c *Client) Home(ctx context.Context, axes []string) error {
+	gcode := "G28"
+	for _, axis := range axes {
+		gcode += " " + strings.ToUpper(axis)
 	}
+	return c.ExecuteGcode(ctx, gcode)
 }
 
-func (c *Client) handleGcodeCommand(ctx context.Context, params map[string]interface{}) error {
-	script, ok := params["script"].(string)
-	if !ok {
-		return fmt.Errorf("missing or invalid 'script' parameter")
+func (c *Client) SetGcodeOffset(ctx context.Context, z *float64, zAdjust *float64, move bool) error {
+	gcode := "SET_GCODE_OFFSET"
+	if z != nil {
+		gcode += fmt.Sprintf(" Z=%.3f", *z)
 	}
-	return c.ExecuteGcode(ctx, script)
+	if zAdjust != nil {
+		gcode += fmt.Sprintf(" Z_ADJUST=%.3f", *zAdjust)
+	}
+	if move {
+		gcode += " MOVE=1"
+	}
+	return c.ExecuteGcode(ctx, gcode)
 }
 
 func (l *clientListener) OnStateChanged(state string) {
@@ -247,6 +340,10 @@ func (l *clientListener) OnStateChanged(state string) {
 }
 
 func (l *clientListener) OnNotification(method string, params any) {
+	if method == "notify_gcode_response" {
+		l.client.recordConsole(ParseGcodeResponse(params))
+	}
+
 	if l.parent != nil {
 		l.parent.OnNotification(method, params)
 	}
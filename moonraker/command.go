@@ -0,0 +1,297 @@
+package moonraker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+func (c *Client) HandleCommand(ctx context.Context, payload []byte) *CommandResult {
+	start := time.Now()
+
+	var cmdMsg CommandMessage
+	if err := json.Unmarshal(payload, &cmdMsg); err != nil {
+		c.logger.Error("Failed to parse command message: %v", err)
+		return newCommandResult(&cmdMsg, nil, NewCommandError(COMMAND_ERROR_PARSE, fmt.Sprintf("failed to parse command message: %v", err)), start)
+	}
+
+	if cmdMsg.Timeout < 0 {
+		return newCommandResult(&cmdMsg, nil, NewInvalidParameterError("timeout", "must be a positive number of seconds"), start)
+	}
+
+	if cmdMsg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cmdMsg.Timeout*float64(time.Second)))
+		defer cancel()
+	}
+
+	var capture *consoleCapture
+	if cmdMsg.Command == "gcode" {
+		capture = c.startConsoleCapture()
+	}
+
+	logger := c.logger.With("command", cmdMsg.Command)
+	if cmdMsg.ID != "" {
+		logger = logger.With("request_id", cmdMsg.ID)
+	}
+
+	result, err := c.executeCommand(ctx, cmdMsg.Command, cmdMsg.Params)
+	if err != nil {
+		logger.Error("Failed to execute command %s: %v", cmdMsg.Command, err)
+	} else {
+		logger.Info("Successfully executed command: %s", cmdMsg.Command)
+	}
+
+	commandResult := newCommandResult(&cmdMsg, result, err, start)
+	if capture != nil {
+		commandResult.Response = c.stopConsoleCapture(capture)
+	}
+
+	return commandResult
+}
+
+func IsPriorityCommand(command string) bool {
+	return command == "emergency_stop" || command == "cancel"
+}
+
+func (c *Client) executeCommand(ctx context.Context, command string, params map[string]any) (any, error) {
+	switch command {
+	case "gcode":
+		return c.handleGcodeCommand(ctx, params)
+	case "emergency_stop":
+		return nil, c.EmergencyStop(ctx)
+	case "restart":
+		return nil, c.RestartPrinter(ctx)
+	case "firmware_restart":
+		return nil, c.RestartFirmware(ctx)
+	case "pause":
+		return nil, c.PausePrint(ctx)
+	case "resume":
+		return nil, c.ResumePrint(ctx)
+	case "cancel":
+		return nil, c.CancelPrint(ctx)
+	case "start":
+		return c.handleStartCommand(ctx, params)
+	case "set_temperature":
+		return c.handleSetTemperatureCommand(ctx, params)
+	case "set_fan_speed":
+		return c.handleSetFanSpeedCommand(ctx, params)
+	case "set_speed_factor":
+		return c.handleFactorCommand(ctx, params, c.SetSpeedFactor)
+	case "set_flow_factor":
+		return c.handleFactorCommand(ctx, params, c.SetFlowFactor)
+	case "home":
+		return c.handleHomeCommand(ctx, params)
+	case "set_gcode_offset":
+		return c.handleSetGcodeOffsetCommand(ctx, params)
+	case "rpc":
+		return c.handleRPCCommand(ctx, params)
+	default:
+		return nil, NewUnknownCommandError(command)
+	}
+}
+
+func (c *Client) handleGcodeCommand(ctx context.Context, params map[string]any) (any, error) {
+	script, err := stringParam(params, "script")
+	if err != nil {
+		return nil, err
+	}
+	return c.CallMethod(ctx, "printer.gcode.script", map[string]any{
+		"script": script,
+	})
+}
+
+func (c *Client) handleStartCommand(ctx context.Context, params map[string]any) (any, error) {
+	filename, err := stringParam(params, "filename")
+	if err != nil {
+		return nil, err
+	}
+	return nil, c.StartPrint(ctx, filename)
+}
+
+func (c *Client) handleSetTemperatureCommand(ctx context.Context, params map[string]any) (any, error) {
+	heater, err := stringParam(params, "heater")
+	if err != nil {
+		return nil, err
+	}
+
+	heater = strings.TrimPrefix(heater, "heater_generic ")
+	if !isIdentifier(heater) {
+		return nil, NewInvalidParameterError("heater", "must only contain letters, digits, '_' and '-'")
+	}
+
+	target, err := numberParam(params, "target", 0, MAX_HEATER_TARGET)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.SetHeaterTemperature(ctx, heater, target)
+}
+
+func (c *Client) handleSetFanSpeedCommand(ctx context.Context, params map[string]any) (any, error) {
+	fan := ""
+	if _, exists := params["fan"]; exists {
+		name, err := stringParam(params, "fan")
+		if err != nil {
+			return nil, err
+		}
+
+		fan = strings.TrimPrefix(name, "fan_generic ")
+		if !isIdentifier(fan) {
+			return nil, NewInvalidParameterError("fan", "must only contain letters, digits, '_' and '-'")
+		}
+	}
+
+	speed, err := numberParam(params, "speed", 0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.SetFanSpeed(ctx, fan, speed)
+}
+
+func (c *Client) handleFactorCommand(ctx context.Context, params map[string]any, apply func(context.Context, float64) error) (any, error) {
+	factor, err := numberParam(params, "factor", MIN_FACTOR_PERCENT, MAX_FACTOR_PERCENT)
+	if err != nil {
+		return nil, err
+	}
+	return nil, apply(ctx, factor)
+}
+
+func (c *Client) handleHomeCommand(ctx context.Context, params map[string]any) (any, error) {
+	var axes []string
+
+	if value, exists := params["axes"]; exists {
+		items, ok := value.([]any)
+		if !ok {
+			return nil, NewInvalidParameterError("axes", "must be an array of axis names")
+		}
+
+		for _, item := range items {
+			axis, ok := item.(string)
+			if !ok {
+				return nil, NewInvalidParameterError("axes", "must be an array of axis names")
+			}
+
+			axis = strings.ToLower(axis)
+			if axis != "x" && axis != "y" && axis != "z" {
+				return nil, NewInvalidParameterError("axes", fmt.Sprintf("unknown axis '%s', must be x, y or z", axis))
+			}
+			axes = append(axes, axis)
+		}
+	}
+
+	return nil, c.Home(ctx, axes)
+}
+
+func (c *Client) handleSetGcodeOffsetCommand(ctx context.Context, params map[string]any) (any, error) {
+	var z, zAdjust *float64
+
+	if _, exists := params["z"]; exists {
+		value, err := numberParam(params, "z", -MAX_GCODE_OFFSET, MAX_GCODE_OFFSET)
+		if err != nil {
+			return nil, err
+		}
+		z = &value
+	}
+
+	if _, exists := params["z_adjust"]; exists {
+		value, err := numberParam(params, "z_adjust", -MAX_GCODE_OFFSET, MAX_GCODE_OFFSET)
+		if err != nil {
+			return nil, err
+		}
+		zAdjust = &value
+	}
+
+	if z == nil && zAdjust == nil {
+		return nil, NewInvalidParameterError("z_adjust", "either 'z' or 'z_adjust' is required")
+	}
+
+	move := true
+	if value, exists := params["move"]; exists {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, NewInvalidParameterError("move", "must be a boolean")
+		}
+		move = b
+	}
+
+	return nil, c.SetGcodeOffset(ctx, z, zAdjust, move)
+}
+
+func (c *Client) handleRPCCommand(ctx context.Context, params map[string]any) (any, error) {
+	method, err := stringParam(params, "method")
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.isRPCMethodAllowed(method) {
+		return nil, NewMethodNotAllowedError(method)
+	}
+
+	rpcParams := params["params"]
+	switch rpcParams.(type) {
+	case nil, map[string]any, []any:
+	default:
+		return nil, NewInvalidParameterError("params", "must be an object or an array")
+	}
+
+	return c.CallMethod(ctx, method, rpcParams)
+}
+
+func stringParam(params map[string]any, name string) (string, error) {
+	value, ok := params[name].(string)
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", NewInvalidParameterError(name, "missing or not a string")
+	}
+	return value, nil
+}
+
+func numberParam(params map[string]any, name string, min, max float64) (float64, error) {
+	value, ok := params[name].(float64)
+	if !ok {
+		return 0, NewInvalidParameterError(name, "missing or not a number")
+	}
+
+	if math.IsNaN(value) || value < min || value > max {
+		return 0, NewInvalidParameterError(name, fmt.Sprintf("must be between %g and %g, got %g", min, max, value))
+	}
+
+	return value, nil
+}
+
+func isIdentifier(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newCommandResult(cmdMsg *CommandMessage, result any, err error, start time.Time) *CommandResult {
+	commandResult := &CommandResult{
+		ID:         cmdMsg.ID,
+		Command:    cmdMsg.Command,
+		Status:     COMMAND_STATUS_SUCCESS,
+		Result:     result,
+		DurationMS: time.Since(start).Milliseconds(),
+		ReplyTo:    cmdMsg.ReplyTo,
+	}
+
+	if err != nil {
+		commandResult.Status = COMMAND_STATUS_ERROR
+		commandResult.Result = nil
+		commandResult.Error = ToCommandError(err)
+	}
+
+	return commandResult
+}
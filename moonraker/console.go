@@ -0,0 +1,43 @@
+package moonraker
+
+func ParseGcodeResponse(params any) []string {
+	items, ok := params.([]any)
+	if !ok {
+		return nil
+	}
+
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		if line, ok := item.(string); ok {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (c *Client) startConsoleCapture() *consoleCapture {
+	capture := &consoleCapture{}
+
+	c.consoleMux.Lock()
+	defer c.consoleMux.Unlock()
+
+	c.consoleCaptures[capture] = struct{}{}
+	return capture
+}
+
+func (c *Client) stopConsoleCapture(capture *consoleCapture) []string {
+	c.consoleMux.Lock()
+	defer c.consoleMux.Unlock()
+
+	delete(c.consoleCaptures, capture)
+	return capture.lines
+}
+
+func (c *Client) recordConsole(lines []string) {
+	c.consoleMux.Lock()
+	defer c.consoleMux.Unlock()
+
+	for capture := range c.consoleCaptures {
+		capture.lines = append(capture.lines, lines...)
+	}
+}
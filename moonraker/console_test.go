@@ -0,0 +1,35 @@
+package moonraker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGcodeResponse(t *testing.T) {
+	lines := ParseGcodeResponse([]any{"// probe: open", 42, "ok"})
+	if want := []string{"// probe: open", "ok"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("ParseGcodeResponse() = %v, want %v", lines, want)
+	}
+
+	if lines := ParseGcodeResponse("not a list"); lines != nil {
+		t.Errorf("ParseGcodeResponse() = %v, want nil", lines)
+	}
+}
+
+func TestClient_ConsoleCapture(t *testing.T) {
+	client := &Client{consoleCaptures: make(map[*consoleCapture]struct{})}
+
+	client.recordConsole([]string{"before"})
+
+	capture := client.startConsoleCapture()
+	client.recordConsole([]string{"B:60.1 /60.0 T0:210.3 /210.0"})
+	client.recordConsole([]string{"// Klipper state: Ready"})
+	lines := client.stopConsoleCapture(capture)
+
+	client.recordConsole([]string{"after"})
+
+	want := []string{"B:60.1 /60.0 T0:210.3 /210.0", "// Klipper state: Ready"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("console capture = %v, want %v", lines, want)
+	}
+}
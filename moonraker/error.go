@@ -0,0 +1,78 @@
+package moonraker
+
+import (
+	"errors"
+	"fmt"
+
+	"moonraker2mqtt/websocket"
+)
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command: %s", e.command)
+}
+
+func (e *InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter '%s': %s", e.name, e.reason)
+}
+
+func (e *MethodNotAllowedError) Error() string {
+	return fmt.Sprintf("method not allowed: %s", e.method)
+}
+
+func NewCommandError(code int, message string) *CommandError {
+	return &CommandError{Code: code, Message: message}
+}
+
+func NewUnknownCommandError(command string) *UnknownCommandError {
+	return &UnknownCommandError{command: command}
+}
+
+func NewInvalidParameterError(name, reason string) *InvalidParameterError {
+	return &InvalidParameterError{name: name, reason: reason}
+}
+
+func NewMethodNotAllowedError(method string) *MethodNotAllowedError {
+	return &MethodNotAllowedError{method: method}
+}
+
+func ToCommandError(err error) *CommandError {
+	if err == nil {
+		return nil
+	}
+
+	var commandErr *CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr
+	}
+
+	var unknownErr *UnknownCommandError
+	if errors.As(err, &unknownErr) {
+		return &CommandError{Code: COMMAND_ERROR_UNKNOWN, Message: unknownErr.Error()}
+	}
+
+	var paramErr *InvalidParameterError
+	if errors.As(err, &paramErr) {
+		return &CommandError{Code: COMMAND_ERROR_INVALID_PARAMS, Message: paramErr.Error()}
+	}
+
+	var forbiddenErr *MethodNotAllowedError
+	if errors.As(err, &forbiddenErr) {
+		return &CommandError{Code: COMMAND_ERROR_FORBIDDEN, Message: forbiddenErr.Error()}
+	}
+
+	var timeoutErr *websocket.RequestTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return &CommandError{Code: COMMAND_ERROR_TIMEOUT, Message: timeoutErr.Error()}
+	}
+
+	var rpcErr *websocket.RPCError
+	if errors.As(err, &rpcErr) {
+		return &CommandError{Code: rpcErr.Code, Message: rpcErr.Message}
+	}
+
+	return &CommandError{Code: COMMAND_ERROR_INTERNAL, Message: err.Error()}
+}
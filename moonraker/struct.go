@@ -0,0 +1,61 @@
+package moonraker
+
+const (
+	LOG_COMPONENT = "moonraker"
+
+	COMMAND_STATUS_SUCCESS = "success"
+	COMMAND_STATUS_ERROR   = "error"
+
+	COMMAND_ERROR_PARSE          = -32700
+	COMMAND_ERROR_UNKNOWN        = -32601
+	COMMAND_ERROR_INVALID_PARAMS = -32602
+	COMMAND_ERROR_INTERNAL       = -32603
+	COMMAND_ERROR_FORBIDDEN      = 403
+	COMMAND_ERROR_TIMEOUT        = 408
+
+	MAX_HEATER_TARGET  = 500
+	MIN_FACTOR_PERCENT = 1
+	MAX_FACTOR_PERCENT = 500
+	MAX_GCODE_OFFSET   = 5
+)
+
+type CommandMessage struct {
+	ID      string         `json:"id,omitempty"`
+	Command string         `json:"command"`
+	Params  map[string]any `json:"params"`
+	ReplyTo string         `json:"reply_to,omitempty"`
+	Timeout float64        `json:"timeout,omitempty"`
+}
+
+type CommandResult struct {
+	ID         string        `json:"id,omitempty"`
+	Command    string        `json:"command"`
+	Status     string        `json:"status"`
+	Result     any           `json:"result,omitempty"`
+	Error      *CommandError `json:"error,omitempty"`
+	Response   []string      `json:"response,omitempty"`
+	DurationMS int64         `json:"duration_ms"`
+	ReplyTo    string        `json:"-"`
+}
+
+type UnknownCommandError struct {
+	command string
+}
+
+type InvalidParameterError struct {
+	name   string
+	reason string
+}
+
+type MethodNotAllowedError struct {
+	method string
+}
+
+type CommandError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type consoleCapture struct {
+	lines []string
+}
@@ -0,0 +1,157 @@
+// Package mqtttest provides an in-process MQTT broker for tests.
+package mqtttest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Broker acknowledges just enough of MQTT 3.1.1 for a client to connect,
+// subscribe and publish, and records the topics it was sent.
+type Broker struct {
+	listener      net.Listener
+	published     []string
+	subscriptions []string
+	mux           sync.Mutex
+}
+
+func NewBroker(t *testing.T) *Broker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	broker := &Broker{listener: listener}
+	go broker.serve()
+	return broker
+}
+
+func (b *Broker) Port() int {
+	return b.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (b *Broker) Published(topic string) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return slices.Contains(b.published, topic)
+}
+
+func (b *Broker) Subscribed(topic string) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return slices.Contains(b.subscriptions, topic)
+}
+
+func (b *Broker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *Broker) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		header, body, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+
+		var reply []byte
+		switch header >> 4 {
+		case 1:
+			reply = []byte{0x20, 0x02, 0x00, 0x00}
+		case 3:
+			reply = b.handlePublish(header, body)
+		case 6:
+			reply = []byte{0x70, 0x02, body[0], body[1]}
+		case 8:
+			b.handleSubscribe(body[2:])
+			reply = []byte{0x90, 0x03, body[0], body[1], 0x00}
+		case 10:
+			reply = []byte{0xb0, 0x02, body[0], body[1]}
+		case 12:
+			reply = []byte{0xd0, 0x00}
+		case 14:
+			return
+		}
+
+		if reply != nil {
+			if _, err := conn.Write(reply); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (b *Broker) handlePublish(header byte, body []byte) []byte {
+	topic, rest := readString(body)
+
+	b.mux.Lock()
+	b.published = append(b.published, topic)
+	b.mux.Unlock()
+
+	switch (header >> 1) & 0x03 {
+	case 1:
+		return []byte{0x40, 0x02, rest[0], rest[1]}
+	case 2:
+		return []byte{0x50, 0x02, rest[0], rest[1]}
+	}
+	return nil
+}
+
+func (b *Broker) handleSubscribe(payload []byte) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	for len(payload) > 0 {
+		var topic string
+		topic, payload = readString(payload)
+		b.subscriptions = append(b.subscriptions, topic)
+		payload = payload[1:]
+	}
+}
+
+func readPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func readString(data []byte) (string, []byte) {
+	length := int(binary.BigEndian.Uint16(data))
+	return string(data[2 : 2+length]), data[2+length:]
+}
@@ -1,10 +1,14 @@
 package mqtt
 
 import (
+	"crypto/tls"
 	"fmt"
+	"maps"
+	"sync"
 	"time"
 
 	"moonraker2mqtt/logger"
+	"moonraker2mqtt/metrics"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 )
@@ -20,6 +24,20 @@ type MQTTClient interface {
 
 type MessageHandler func(topic string, payload []byte)
 
+const (
+	AVAILABILITY_ONLINE  = "online"
+	AVAILABILITY_OFFLINE = "offline"
+
+	LOG_COMPONENT = "mqtt"
+)
+
+type StatusMessage struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
 type PahoClient struct {
 	host        string
 	port        int
@@ -27,9 +45,13 @@ type PahoClient struct {
 	username    string
 	password    string
 	useTLS      bool
+	tlsConfig   *tls.Config
+	will        *StatusMessage
+	birth       *StatusMessage
 	client      mqtt.Client
 	logger      logger.Logger
 	subscribers map[string]MessageHandler
+	mux         sync.RWMutex
 }
 
 func NewPahoClient(host string, port int, clientID, username, password string, useTLS bool, logger logger.Logger) *PahoClient {
@@ -40,12 +62,79 @@ func NewPahoClient(host string, port int, clientID, username, password string, u
 		username:    username,
 		password:    password,
 		useTLS:      useTLS,
-		logger:      logger,
+		logger:      logger.WithComponent(LOG_COMPONENT),
 		subscribers: make(map[string]MessageHandler),
 	}
 }
 
+func (c *PahoClient) SetConnection(host string, port int, clientID, username, password string, useTLS bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.host = host
+	c.port = port
+	c.clientID = clientID
+	c.username = username
+	c.password = password
+	c.useTLS = useTLS
+}
+
+func (c *PahoClient) SetWill(topic string, payload []byte, qos byte, retain bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.will = &StatusMessage{Topic: topic, Payload: payload, QoS: qos, Retain: retain}
+}
+
+func (c *PahoClient) SetTLSConfig(tlsConfig *tls.Config) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.tlsConfig = tlsConfig
+}
+
+func (c *PahoClient) SetBirth(topic string, payload []byte, qos byte, retain bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.birth = &StatusMessage{Topic: topic, Payload: payload, QoS: qos, Retain: retain}
+}
+
+func (c *PahoClient) ClearStatusMessages() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.will = nil
+	c.birth = nil
+}
+
 func (c *PahoClient) Connect() error {
+	opts, brokerURL := c.clientOptions()
+
+	opts.SetKeepAlive(60 * time.Second)
+	opts.SetDefaultPublishHandler(c.defaultMessageHandler)
+	opts.SetPingTimeout(30 * time.Second)
+	opts.SetConnectTimeout(30 * time.Second)
+	opts.SetAutoReconnect(false)
+	opts.SetConnectionLostHandler(c.connectionLostHandler)
+	opts.SetOnConnectHandler(c.onConnectHandler)
+
+	client := mqtt.NewClient(opts)
+
+	c.mux.Lock()
+	c.client = client
+	c.mux.Unlock()
+
+	c.logger.Info("Connecting to MQTT broker at %s", brokerURL)
+
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return fmt.Errorf("failed to connect to MQTT broker: %w", token.Error())
+	}
+
+	c.logger.Info("Successfully connected to MQTT broker")
+	return nil
+}
+
+func (c *PahoClient) clientOptions() (*mqtt.ClientOptions, string) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
 	opts := mqtt.NewClientOptions()
 	scheme := "tcp"
 	if c.useTLS {
@@ -56,6 +145,10 @@ func (c *PahoClient) Connect() error {
 	opts.AddBroker(brokerURL)
 	opts.SetClientID(c.clientID)
 
+	if c.useTLS && c.tlsConfig != nil {
+		opts.SetTLSConfig(c.tlsConfig)
+	}
+
 	if c.username != "" {
 		opts.SetUsername(c.username)
 	}
@@ -64,73 +157,85 @@ func (c *PahoClient) Connect() error {
 		opts.SetPassword(c.password)
 	}
 
-	opts.SetKeepAlive(60 * time.Second)
-	opts.SetDefaultPublishHandler(c.defaultMessageHandler)
-	opts.SetPingTimeout(30 * time.Second)
-	opts.SetConnectTimeout(30 * time.Second)
-	opts.SetAutoReconnect(true)
-	opts.SetMaxReconnectInterval(10 * time.Second)
-	opts.SetConnectionLostHandler(c.connectionLostHandler)
-	opts.SetOnConnectHandler(c.onConnectHandler)
-	opts.SetReconnectingHandler(c.reconnectingHandler)
-
-	c.client = mqtt.NewClient(opts)
-
-	c.logger.Info("Connecting to MQTT broker at %s", brokerURL)
-
-	if token := c.client.Connect(); token.Wait() && token.Error() != nil {
-		return fmt.Errorf("failed to connect to MQTT broker: %w", token.Error())
+	if c.will != nil {
+		opts.SetBinaryWill(c.will.Topic, c.will.Payload, c.will.QoS, c.will.Retain)
 	}
 
-	c.logger.Info("Successfully connected to MQTT broker")
-	return nil
+	return opts, brokerURL
 }
 
 func (c *PahoClient) Disconnect() error {
-	if c.client != nil && c.client.IsConnected() {
+	c.mux.RLock()
+	client, will := c.client, c.will
+	c.mux.RUnlock()
+
+	if client != nil && client.IsConnected() {
+		if will != nil {
+			token := client.Publish(will.Topic, will.QoS, will.Retain, will.Payload)
+			if token.WaitTimeout(time.Second) && token.Error() != nil {
+				c.logger.Warn("Failed to publish offline status before disconnecting: %v", token.Error())
+			}
+		}
+
 		c.logger.Info("Disconnecting from MQTT broker")
-		c.client.Disconnect(250)
+		client.Disconnect(250)
 	}
 	return nil
 }
 
 func (c *PahoClient) IsConnected() bool {
-	if c.client == nil {
+	client := c.currentClient()
+	if client == nil {
 		return false
 	}
-	return c.client.IsConnected()
+	return client.IsConnected()
+}
+
+func (c *PahoClient) currentClient() mqtt.Client {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.client
 }
 
 func (c *PahoClient) Publish(topic string, payload []byte, qos byte, retain bool, maxRetries int) error {
-	c.logger.Debug("Publishing message to topic:%s, payload:%s, qos:%d, retain:%t", topic, string(payload), qos, retain)
+	c.logger.With("topic", topic).Debug("Publishing message, payload:%s, qos:%d, retain:%t", string(payload), qos, retain)
 
-	if !c.IsConnected() {
+	client := c.currentClient()
+	if client == nil || !client.IsConnected() {
+		metrics.MQTTPublishFailures.Inc()
 		return fmt.Errorf("not connected to MQTT broker")
 	}
 
-	token := c.client.Publish(topic, qos, retain, payload)
+	token := client.Publish(topic, qos, retain, payload)
 	if token.Wait() && token.Error() != nil {
+		metrics.MQTTPublishFailures.Inc()
 		return fmt.Errorf("failed to publish message: %w", token.Error())
 	}
 
+	metrics.MQTTPublishes.Inc()
 	return nil
 }
 
 func (c *PahoClient) Subscribe(topic string, handler MessageHandler) error {
-	if !c.IsConnected() {
+	client := c.currentClient()
+	if client == nil || !client.IsConnected() {
 		return fmt.Errorf("not connected to MQTT broker")
 	}
 
+	c.mux.Lock()
 	c.subscribers[topic] = handler
+	c.mux.Unlock()
 
-	token := c.client.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
-		if handler, exists := c.subscribers[msg.Topic()]; exists {
+	token := client.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
+		if handler, exists := c.subscriber(msg.Topic()); exists {
 			handler(msg.Topic(), msg.Payload())
 		}
 	})
 
 	if token.Wait() && token.Error() != nil {
+		c.mux.Lock()
 		delete(c.subscribers, topic)
+		c.mux.Unlock()
 		return fmt.Errorf("failed to subscribe to topic %s: %w", topic, token.Error())
 	}
 
@@ -139,22 +244,32 @@ func (c *PahoClient) Subscribe(topic string, handler MessageHandler) error {
 }
 
 func (c *PahoClient) Unsubscribe(topic string) error {
-	if !c.IsConnected() {
+	client := c.currentClient()
+	if client == nil || !client.IsConnected() {
 		return fmt.Errorf("not connected to MQTT broker")
 	}
 
-	token := c.client.Unsubscribe(topic)
+	token := client.Unsubscribe(topic)
 	if token.Wait() && token.Error() != nil {
 		return fmt.Errorf("failed to unsubscribe from topic %s: %w", topic, token.Error())
 	}
 
+	c.mux.Lock()
 	delete(c.subscribers, topic)
+	c.mux.Unlock()
 	c.logger.Info("Successfully unsubscribed from topic: %s", topic)
 	return nil
 }
 
+func (c *PahoClient) subscriber(topic string) (MessageHandler, bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	handler, exists := c.subscribers[topic]
+	return handler, exists
+}
+
 func (c *PahoClient) defaultMessageHandler(client mqtt.Client, msg mqtt.Message) {
-	c.logger.Debug("Received message on topic %s: %s", msg.Topic(), string(msg.Payload()))
+	c.logger.With("topic", msg.Topic()).Debug("Received message: %s", string(msg.Payload()))
 }
 
 func (c *PahoClient) connectionLostHandler(client mqtt.Client, err error) {
@@ -164,7 +279,19 @@ func (c *PahoClient) connectionLostHandler(client mqtt.Client, err error) {
 func (c *PahoClient) onConnectHandler(client mqtt.Client) {
 	c.logger.Info("MQTT connection established")
 
-	for topic, handler := range c.subscribers {
+	c.mux.RLock()
+	birth := c.birth
+	subscribers := maps.Clone(c.subscribers)
+	c.mux.RUnlock()
+
+	if birth != nil {
+		token := client.Publish(birth.Topic, birth.QoS, birth.Retain, birth.Payload)
+		if token.Wait() && token.Error() != nil {
+			c.logger.Error("Failed to publish online status to %s: %v", birth.Topic, token.Error())
+		}
+	}
+
+	for topic, handler := range subscribers {
 		c.logger.Info("Resubscribing to topic: %s", topic)
 		token := client.Subscribe(topic, 0, func(client mqtt.Client, msg mqtt.Message) {
 			handler(msg.Topic(), msg.Payload())
@@ -174,7 +301,3 @@ func (c *PahoClient) onConnectHandler(client mqtt.Client) {
 		}
 	}
 }
-
-func (c *PahoClient) reconnectingHandler(client mqtt.Client, opts *mqtt.ClientOptions) {
-	c.logger.Info("Attempting to reconnect to MQTT broker...")
-}
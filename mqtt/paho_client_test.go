@@ -0,0 +1,73 @@
+package mqtt
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/mqtt/mqtttest"
+)
+
+func TestPahoClient_ConcurrentReconfigureAndPublish(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	log := logger.NewWithWriter(&config.LoggingConfig{Level: "error"}, io.Discard)
+
+	client := NewPahoClient("127.0.0.1", broker.Port(), "test", "", "", false, log)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Disconnect()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			if err := client.Disconnect(); err != nil {
+				t.Errorf("Disconnect() error = %v", err)
+			}
+			client.SetConnection("127.0.0.1", broker.Port(), fmt.Sprintf("test-%d", i), "", "", false)
+			client.SetTLSConfig(nil)
+			client.SetWill("moonraker/bridge/availability", []byte(AVAILABILITY_OFFLINE), 0, true)
+			client.SetBirth("moonraker/bridge/availability", []byte(AVAILABILITY_ONLINE), 0, true)
+			if err := client.Connect(); err != nil {
+				t.Errorf("Connect() error = %v", err)
+			}
+		}
+	}()
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			topic := fmt.Sprintf("moonraker/test/%d", i)
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				client.IsConnected()
+				_ = client.Publish(topic, []byte("payload"), 0, false, 1)
+				_ = client.Subscribe(topic, func(topic string, payload []byte) {})
+				_ = client.Unsubscribe(topic)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if !client.IsConnected() {
+		t.Fatal("IsConnected() = false after the last reconnect")
+	}
+	if err := client.Publish("moonraker/test", []byte("payload"), 0, false, 1); err != nil {
+		t.Errorf("Publish() error = %v after reconnecting", err)
+	}
+}
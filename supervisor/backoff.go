@@ -0,0 +1,52 @@
+package supervisor
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+func NewPolicy(enabled bool, maxAttempts int) Policy {
+	return Policy{
+		Enabled:      enabled,
+		MaxAttempts:  maxAttempts,
+		InitialDelay: DEFAULT_INITIAL_DELAY,
+		MaxDelay:     DEFAULT_MAX_DELAY,
+		Multiplier:   DEFAULT_MULTIPLIER,
+		Jitter:       DEFAULT_JITTER,
+	}
+}
+
+func NewBackoff(policy Policy) *Backoff {
+	return &Backoff{
+		policy: policy,
+		random: rand.Float64,
+	}
+}
+
+func (b *Backoff) Next() (time.Duration, bool) {
+	if b.policy.MaxAttempts > 0 && b.attempt >= b.policy.MaxAttempts {
+		return 0, false
+	}
+
+	b.attempt++
+
+	delay := float64(b.policy.InitialDelay) * math.Pow(b.policy.Multiplier, float64(b.attempt-1))
+	if maxDelay := float64(b.policy.MaxDelay); maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if b.policy.Jitter > 0 {
+		delay += delay * b.policy.Jitter * (2*b.random() - 1)
+	}
+
+	return time.Duration(delay), true
+}
+
+func (b *Backoff) Attempt() int {
+	return b.attempt
+}
+
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
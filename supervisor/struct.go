@@ -0,0 +1,78 @@
+package supervisor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"moonraker2mqtt/logger"
+)
+
+const (
+	LOG_COMPONENT = "supervisor"
+
+	LINK_STATE_CONNECTED    = "connected"
+	LINK_STATE_DISCONNECTED = "disconnected"
+	LINK_STATE_RECONNECTING = "reconnecting"
+	LINK_STATE_FAILED       = "failed"
+
+	DEFAULT_CHECK_INTERVAL = time.Second
+	DEFAULT_INITIAL_DELAY  = time.Second
+	DEFAULT_MAX_DELAY      = 60 * time.Second
+	DEFAULT_MULTIPLIER     = 2
+	DEFAULT_JITTER         = 0.2
+)
+
+type Link interface {
+	Name() string
+	Connect(ctx context.Context) error
+	IsConnected() bool
+}
+
+type EventHandler func(event Event)
+
+type Event struct {
+	Link    string
+	State   string
+	Attempt int
+	Delay   time.Duration
+	Err     error
+	Time    time.Time
+}
+
+type Policy struct {
+	Enabled      bool
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+}
+
+type Backoff struct {
+	policy  Policy
+	attempt int
+	random  func() float64
+}
+
+type funcLink struct {
+	name        string
+	connect     func(ctx context.Context) error
+	isConnected func() bool
+}
+
+type supervisedLink struct {
+	link    Link
+	policy  Policy
+	backoff *Backoff
+	state   string
+	reset   chan Policy
+}
+
+type Supervisor struct {
+	links         []*supervisedLink
+	handlers      []EventHandler
+	checkInterval time.Duration
+	mux           sync.RWMutex
+	logger        logger.Logger
+}
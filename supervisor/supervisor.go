@@ -0,0 +1,227 @@
+package supervisor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"moonraker2mqtt/logger"
+	"moonraker2mqtt/metrics"
+)
+
+func New(checkInterval time.Duration, logger logger.Logger) *Supervisor {
+	if checkInterval <= 0 {
+		checkInterval = DEFAULT_CHECK_INTERVAL
+	}
+
+	return &Supervisor{
+		checkInterval: checkInterval,
+		logger:        logger.WithComponent(LOG_COMPONENT),
+	}
+}
+
+func NewLink(name string, connect func(ctx context.Context) error, isConnected func() bool) Link {
+	return &funcLink{
+		name:        name,
+		connect:     connect,
+		isConnected: isConnected,
+	}
+}
+
+func (l *funcLink) Name() string {
+	return l.name
+}
+
+func (l *funcLink) Connect(ctx context.Context) error {
+	return l.connect(ctx)
+}
+
+func (l *funcLink) IsConnected() bool {
+	return l.isConnected()
+}
+
+func (s *Supervisor) Add(link Link, policy Policy) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.links = append(s.links, &supervisedLink{
+		link:    link,
+		policy:  policy,
+		backoff: NewBackoff(policy),
+		reset:   make(chan Policy, 1),
+	})
+}
+
+func (s *Supervisor) Reset(name string, policy Policy) bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	for _, link := range s.links {
+		if link.link.Name() != name {
+			continue
+		}
+
+		select {
+		case <-link.reset:
+		default:
+		}
+		link.reset <- policy
+		return true
+	}
+
+	return false
+}
+
+func (s *Supervisor) OnEvent(handler EventHandler) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *Supervisor) States() map[string]string {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	states := make(map[string]string, len(s.links))
+	for _, link := range s.links {
+		if link.state != "" {
+			states[link.link.Name()] = link.state
+		}
+	}
+	return states
+}
+
+func (s *Supervisor) Run(ctx context.Context) {
+	s.mux.RLock()
+	links := make([]*supervisedLink, len(s.links))
+	copy(links, s.links)
+	s.mux.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, link := range links {
+		wg.Add(1)
+		go func(link *supervisedLink) {
+			defer wg.Done()
+			s.supervise(ctx, link)
+		}(link)
+	}
+	wg.Wait()
+}
+
+func (s *Supervisor) supervise(ctx context.Context, link *supervisedLink) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.check(ctx, link)
+
+		select {
+		case <-ctx.Done():
+			return
+		case policy := <-link.reset:
+			s.applyReset(link, policy)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Supervisor) applyReset(link *supervisedLink, policy Policy) {
+	link.policy = policy
+	link.backoff = NewBackoff(policy)
+
+	if s.state(link) == LINK_STATE_FAILED {
+		s.emit(link, Event{State: LINK_STATE_DISCONNECTED})
+	}
+}
+
+func (s *Supervisor) check(ctx context.Context, link *supervisedLink) {
+	if link.link.IsConnected() {
+		if s.state(link) != LINK_STATE_CONNECTED {
+			link.backoff.Reset()
+			s.emit(link, Event{State: LINK_STATE_CONNECTED})
+		}
+		return
+	}
+
+	switch s.state(link) {
+	case LINK_STATE_FAILED:
+		return
+	case "", LINK_STATE_CONNECTED:
+		s.emit(link, Event{State: LINK_STATE_DISCONNECTED})
+	}
+
+	if !link.policy.Enabled {
+		return
+	}
+
+	delay, ok := link.backoff.Next()
+	if !ok {
+		s.emit(link, Event{State: LINK_STATE_FAILED, Attempt: link.backoff.Attempt()})
+		return
+	}
+
+	s.emit(link, Event{State: LINK_STATE_RECONNECTING, Attempt: link.backoff.Attempt(), Delay: delay})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return
+	case policy := <-link.reset:
+		s.applyReset(link, policy)
+		return
+	case <-timer.C:
+	}
+
+	if link.link.IsConnected() {
+		return
+	}
+
+	if err := link.link.Connect(ctx); err != nil {
+		metrics.Reconnects.Inc(link.link.Name(), metrics.RESULT_ERROR)
+		s.emit(link, Event{State: LINK_STATE_DISCONNECTED, Attempt: link.backoff.Attempt(), Err: err})
+		return
+	}
+
+	metrics.Reconnects.Inc(link.link.Name(), metrics.RESULT_SUCCESS)
+
+	if link.link.IsConnected() {
+		link.backoff.Reset()
+		s.emit(link, Event{State: LINK_STATE_CONNECTED})
+	}
+}
+
+func (s *Supervisor) state(link *supervisedLink) string {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return link.state
+}
+
+func (s *Supervisor) emit(link *supervisedLink, event Event) {
+	event.Link = link.link.Name()
+	event.Time = time.Now()
+
+	s.mux.Lock()
+	link.state = event.State
+	handlers := make([]EventHandler, len(s.handlers))
+	copy(handlers, s.handlers)
+	s.mux.Unlock()
+
+	logger := s.logger.With("link", event.Link, "state", event.State)
+	switch {
+	case event.Err != nil:
+		logger.Warn("Link %s reconnection attempt %d failed: %v", event.Link, event.Attempt, event.Err)
+	case event.State == LINK_STATE_RECONNECTING:
+		logger.Info("Link %s reconnecting in %s (attempt %d)", event.Link, event.Delay.Round(time.Millisecond), event.Attempt)
+	case event.State == LINK_STATE_FAILED:
+		logger.Error("Link %s gave up after %d reconnection attempts", event.Link, event.Attempt)
+	default:
+		logger.Info("Link %s is %s", event.Link, event.State)
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
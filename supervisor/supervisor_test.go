@@ -0,0 +1,264 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/logger"
+)
+
+type testLogger struct{}
+
+func (testLogger) Debug(format string, args ...any)               {}
+func (testLogger) Info(format string, args ...any)                {}
+func (testLogger) Warn(format string, args ...any)                {}
+func (testLogger) Error(format string, args ...any)               {}
+func (testLogger) SetLevel(level logger.LogLevel)                 {}
+func (testLogger) GetLevel() logger.LogLevel                      { return logger.DEBUG }
+func (l testLogger) With(fields ...any) logger.Logger             { return l }
+func (l testLogger) WithComponent(component string) logger.Logger { return l }
+
+type fakeLink struct {
+	connected    atomic.Bool
+	attempts     atomic.Int32
+	failAttempts int32
+}
+
+func (l *fakeLink) Name() string { return "fake" }
+
+func (l *fakeLink) Connect(ctx context.Context) error {
+	if l.attempts.Add(1) <= l.failAttempts {
+		return errors.New("connection refused")
+	}
+	l.connected.Store(true)
+	return nil
+}
+
+func (l *fakeLink) IsConnected() bool { return l.connected.Load() }
+
+type eventRecorder struct {
+	events []Event
+	mux    sync.Mutex
+}
+
+func (r *eventRecorder) handle(event Event) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) states() []string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	states := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		states = append(states, event.State)
+	}
+	return states
+}
+
+func testPolicy(enabled bool, maxAttempts int) Policy {
+	return Policy{
+		Enabled:      enabled,
+		MaxAttempts:  maxAttempts,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+		Multiplier:   2,
+	}
+}
+
+func runSupervisor(t *testing.T, link Link, policy Policy, until func(recorder *eventRecorder) bool) *eventRecorder {
+	t.Helper()
+
+	recorder := &eventRecorder{}
+	supervisor := New(5*time.Millisecond, testLogger{})
+	supervisor.Add(link, policy)
+	supervisor.OnEvent(recorder.handle)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		supervisor.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !until(recorder) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	return recorder
+}
+
+func equalStates(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBackoff_Next(t *testing.T) {
+	backoff := NewBackoff(Policy{
+		MaxAttempts:  5,
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.5,
+	})
+	backoff.random = func() float64 { return 0.5 }
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, expected := range want {
+		delay, ok := backoff.Next()
+		if !ok {
+			t.Fatalf("Next() attempt %d returned false", i+1)
+		}
+		if delay != expected {
+			t.Errorf("Next() attempt %d = %v, want %v", i+1, delay, expected)
+		}
+	}
+
+	if _, ok := backoff.Next(); ok {
+		t.Error("Next() after max attempts returned true")
+	}
+
+	backoff.Reset()
+	if delay, ok := backoff.Next(); !ok || delay != time.Second {
+		t.Errorf("Next() after Reset() = %v, %t, want 1s, true", delay, ok)
+	}
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	policy := Policy{InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2, Jitter: 0.2}
+
+	tests := []struct {
+		random float64
+		want   time.Duration
+	}{
+		{0, 800 * time.Millisecond},
+		{0.5, time.Second},
+		{1, 1200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		backoff := NewBackoff(policy)
+		backoff.random = func() float64 { return tt.random }
+
+		if delay, _ := backoff.Next(); delay != tt.want {
+			t.Errorf("Next() with random %v = %v, want %v", tt.random, delay, tt.want)
+		}
+	}
+}
+
+func TestSupervisor_Reconnects(t *testing.T) {
+	link := &fakeLink{failAttempts: 2}
+
+	recorder := runSupervisor(t, link, testPolicy(true, 0), func(recorder *eventRecorder) bool {
+		return link.IsConnected()
+	})
+
+	want := []string{
+		LINK_STATE_DISCONNECTED,
+		LINK_STATE_RECONNECTING, LINK_STATE_DISCONNECTED,
+		LINK_STATE_RECONNECTING, LINK_STATE_DISCONNECTED,
+		LINK_STATE_RECONNECTING, LINK_STATE_CONNECTED,
+	}
+	if got := recorder.states(); !equalStates(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestSupervisor_GivesUpAfterMaxAttempts(t *testing.T) {
+	link := &fakeLink{failAttempts: 100}
+
+	recorder := runSupervisor(t, link, testPolicy(true, 2), func(recorder *eventRecorder) bool {
+		states := recorder.states()
+		return len(states) > 0 && states[len(states)-1] == LINK_STATE_FAILED
+	})
+
+	if attempts := link.attempts.Load(); attempts != 2 {
+		t.Errorf("Connect() called %d times, want 2", attempts)
+	}
+
+	states := recorder.states()
+	if len(states) == 0 || states[len(states)-1] != LINK_STATE_FAILED {
+		t.Errorf("events = %v, want last state %s", states, LINK_STATE_FAILED)
+	}
+}
+
+func TestSupervisor_AutoReconnectDisabled(t *testing.T) {
+	link := &fakeLink{}
+
+	start := time.Now()
+	recorder := runSupervisor(t, link, testPolicy(false, 0), func(recorder *eventRecorder) bool {
+		return time.Since(start) > 50*time.Millisecond
+	})
+
+	if attempts := link.attempts.Load(); attempts != 0 {
+		t.Errorf("Connect() called %d times with auto reconnect disabled, want 0", attempts)
+	}
+	if got, want := recorder.states(), []string{LINK_STATE_DISCONNECTED}; !equalStates(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestSupervisor_States(t *testing.T) {
+	link := &fakeLink{}
+	link.connected.Store(true)
+
+	supervisor := New(time.Millisecond, testLogger{})
+	supervisor.Add(link, testPolicy(true, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	supervisor.Run(ctx)
+
+	if state := supervisor.States()["fake"]; state != LINK_STATE_CONNECTED {
+		t.Errorf("States()[fake] = %q, want %q", state, LINK_STATE_CONNECTED)
+	}
+}
+
+func TestSupervisor_ResetRetriesFailedLink(t *testing.T) {
+	link := &fakeLink{failAttempts: 2}
+
+	recorder := &eventRecorder{}
+	supervisor := New(5*time.Millisecond, testLogger{})
+	supervisor.Add(link, testPolicy(true, 1))
+	supervisor.OnEvent(recorder.handle)
+
+	if supervisor.Reset("unknown", testPolicy(true, 0)) {
+		t.Error("Reset() of an unknown link returned true")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go supervisor.Run(ctx)
+
+	for supervisor.States()["fake"] != LINK_STATE_FAILED && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if !supervisor.Reset("fake", testPolicy(true, 0)) {
+		t.Fatal("Reset() returned false for a supervised link")
+	}
+
+	for !link.IsConnected() && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if !link.IsConnected() {
+		t.Errorf("link did not reconnect after Reset(), events = %v", recorder.states())
+	}
+}
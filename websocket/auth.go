@@ -0,0 +1,375 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"moonraker2mqtt/version"
+)
+
+func (c *WebSocketClient) authenticate(ctx context.Context) error {
+	cfg := c.currentConfig()
+	if cfg.Username != "" && !c.hasValidToken() {
+		if err := c.login(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := c.identify(ctx); err != nil {
+		return err
+	}
+
+	c.stateMux.Lock()
+	defer c.stateMux.Unlock()
+
+	if c.state != WEB_SOCKET_STATE_AUTHENTICATING {
+		return NewClientNotConnectedError("connection lost during authentication")
+	}
+
+	c.setState(WEB_SOCKET_STATE_CONNECTED)
+
+	if c.refreshToken() != "" {
+		c.startTokenRefresh()
+	}
+
+	return nil
+}
+
+func (c *WebSocketClient) login(ctx context.Context) error {
+	cfg := c.currentConfig()
+	result, err := c.call(ctx, "access.login", c.loginParams())
+	if err != nil {
+		return NewClientNotAuthenticatedError(fmt.Sprintf("login as %s failed: %v", cfg.Username, err))
+	}
+
+	var tokens AuthTokens
+	if err := decodeResult(result, &tokens); err != nil {
+		return NewClientNotAuthenticatedError(fmt.Sprintf("invalid login response: %v", err))
+	}
+
+	c.setTokens(tokens.Token, tokens.RefreshToken)
+	c.logger.Info("Logged in to Moonraker as %s", cfg.Username)
+	return nil
+}
+
+func (c *WebSocketClient) refreshJWT(ctx context.Context) error {
+	result, err := c.call(ctx, "access.refresh_jwt", map[string]any{
+		"refresh_token": c.refreshToken(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh access token: %w", err)
+	}
+
+	var tokens AuthTokens
+	if err := decodeResult(result, &tokens); err != nil {
+		return fmt.Errorf("invalid refresh response: %w", err)
+	}
+
+	c.setTokens(tokens.Token, c.refreshToken())
+	c.logger.Debug("Refreshed Moonraker access token")
+	return nil
+}
+
+func (c *WebSocketClient) identify(ctx context.Context) error {
+	cfg := c.currentConfig()
+	clientType := cfg.GetClientType()
+
+	connectionID, err := c.identifyAs(ctx, clientType)
+
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code != RPC_METHOD_NOT_FOUND && clientType != CLIENT_TYPE_OTHER {
+		c.logger.Warn("Moonraker rejected client type %s (%v), identifying as %s", clientType, err, CLIENT_TYPE_OTHER)
+		connectionID, err = c.identifyAs(ctx, CLIENT_TYPE_OTHER)
+	}
+
+	if errors.As(err, &rpcErr) && rpcErr.Code == RPC_METHOD_NOT_FOUND {
+		c.logger.Warn("Moonraker does not support connection identification, skipping")
+		return nil
+	}
+	if err != nil {
+		return NewClientNotAuthenticatedError(fmt.Sprintf("identification failed: %v", err))
+	}
+
+	c.logger.Debug("Identified with Moonraker as %s (connection %d)", cfg.GetClientName(), connectionID)
+	return nil
+}
+
+func (c *WebSocketClient) identifyAs(ctx context.Context, clientType string) (int, error) {
+	cfg := c.currentConfig()
+	params := map[string]any{
+		"client_name": cfg.GetClientName(),
+		"version":     version.Version,
+		"type":        clientType,
+		"url":         projectURL(),
+	}
+
+	if token := c.accessToken(); token != "" {
+		params["access_token"] = token
+	} else if cfg.APIKey != "" {
+		params["api_key"] = cfg.APIKey
+	}
+
+	result, err := c.call(ctx, "server.connection.identify", params)
+	if err != nil {
+		return 0, err
+	}
+
+	var identity struct {
+		ConnectionID int `json:"connection_id"`
+	}
+	if err := decodeResult(result, &identity); err != nil {
+		return 0, fmt.Errorf("invalid identify response: %w", err)
+	}
+
+	return identity.ConnectionID, nil
+}
+
+func (c *WebSocketClient) call(ctx context.Context, method string, params any) (any, error) {
+	msg, err := c.sendRequest(ctx, method, params, 0)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Result, nil
+}
+
+func (c *WebSocketClient) startTokenRefresh() {
+	c.authMux.Lock()
+	defer c.authMux.Unlock()
+
+	if c.refreshStop != nil {
+		close(c.refreshStop)
+	}
+	c.refreshStop = make(chan struct{})
+
+	go c.tokenRefreshLoop(c.refreshStop)
+}
+
+func (c *WebSocketClient) stopTokenRefresh() {
+	c.authMux.Lock()
+	defer c.authMux.Unlock()
+
+	if c.refreshStop != nil {
+		close(c.refreshStop)
+		c.refreshStop = nil
+	}
+}
+
+func (c *WebSocketClient) tokenRefreshLoop(stop chan struct{}) {
+	cfg := c.currentConfig()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(tokenRefreshDelay(c.accessToken(), time.Now())):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GetTimeout())
+		err := c.refreshJWT(ctx)
+		if err != nil {
+			c.logger.Warn("Failed to refresh Moonraker access token, logging in again: %v", err)
+			err = c.login(ctx)
+		}
+		cancel()
+
+		if err != nil {
+			c.logger.Error("Failed to renew Moonraker access token: %v", err)
+		}
+	}
+}
+
+func (c *WebSocketClient) hasValidToken() bool {
+	expiry, err := tokenExpiry(c.accessToken())
+	if err != nil {
+		return false
+	}
+	return time.Until(expiry) > JWT_REFRESH_MARGIN
+}
+
+func (c *WebSocketClient) accessToken() string {
+	c.authMux.Lock()
+	defer c.authMux.Unlock()
+	return c.token
+}
+
+func (c *WebSocketClient) refreshToken() string {
+	c.authMux.Lock()
+	defer c.authMux.Unlock()
+	return c.refresh
+}
+
+func (c *WebSocketClient) setTokens(token, refresh string) {
+	c.authMux.Lock()
+	defer c.authMux.Unlock()
+	c.token = token
+	c.refresh = refresh
+}
+
+func (c *WebSocketClient) loginParams() map[string]any {
+	cfg := c.currentConfig()
+	params := map[string]any{
+		"username": cfg.Username,
+		"password": cfg.Password,
+	}
+	if cfg.AuthSource != "" {
+		params["source"] = cfg.AuthSource
+	}
+	return params
+}
+
+func (c *WebSocketClient) fetchOneshotToken(ctx context.Context) (string, error) {
+	cfg := c.currentConfig()
+	if cfg.Username != "" && !c.hasValidToken() {
+		if err := c.httpLogin(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	var token string
+	if err := c.httpRequest(ctx, http.MethodGet, "/access/oneshot_token", nil, &token); err != nil {
+		return "", NewClientNotAuthenticatedError(fmt.Sprintf("failed to fetch oneshot token: %v", err))
+	}
+
+	return token, nil
+}
+
+func (c *WebSocketClient) httpLogin(ctx context.Context) error {
+	cfg := c.currentConfig()
+	var tokens AuthTokens
+	if err := c.httpRequest(ctx, http.MethodPost, "/access/login", c.loginParams(), &tokens); err != nil {
+		return NewClientNotAuthenticatedError(fmt.Sprintf("login as %s failed: %v", cfg.Username, err))
+	}
+
+	c.setTokens(tokens.Token, tokens.RefreshToken)
+	c.logger.Info("Logged in to Moonraker as %s", cfg.Username)
+	return nil
+}
+
+func (c *WebSocketClient) httpRequest(ctx context.Context, method, path string, body any, result any) error {
+	cfg := c.currentConfig()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, cfg.GetHTTPURL()+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if token := c.accessToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if cfg.APIKey != "" {
+		req.Header.Set("X-Api-Key", cfg.APIKey)
+	}
+
+	client, err := c.httpClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+	}
+	if envelope.Error != nil {
+		return &RPCError{Code: envelope.Error.Code, Message: envelope.Error.Message}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+	}
+
+	return json.Unmarshal(envelope.Result, result)
+}
+
+func (c *WebSocketClient) httpClient() (*http.Client, error) {
+	cfg := c.currentConfig()
+	tlsConfig, err := cfg.GetTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Timeout: cfg.GetTimeout(), Transport: transport}, nil
+}
+
+func decodeResult(result any, target any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+func tokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("malformed token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed token payload: %w", err)
+	}
+
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if claims.Exp <= 0 {
+		return time.Time{}, fmt.Errorf("token has no expiry")
+	}
+
+	return time.Unix(int64(claims.Exp), 0), nil
+}
+
+func tokenRefreshDelay(token string, now time.Time) time.Duration {
+	expiry, err := tokenExpiry(token)
+	if err != nil {
+		return JWT_DEFAULT_REFRESH_DELAY
+	}
+
+	delay := expiry.Sub(now) - JWT_REFRESH_MARGIN
+	if delay < JWT_MIN_REFRESH_DELAY {
+		return JWT_MIN_REFRESH_DELAY
+	}
+	return delay
+}
+
+func projectURL() string {
+	if version.GitURL == "" || version.GitURL == "unknown" {
+		return PROJECT_URL
+	}
+	return version.GitURL
+}
@@ -0,0 +1,93 @@
+package websocket
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testToken(claims string) string {
+	return fmt.Sprintf("header.%s.signature", base64.RawURLEncoding.EncodeToString([]byte(claims)))
+}
+
+func TestTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"valid token", testToken(`{"exp": 1700000000}`), time.Unix(1700000000, 0), false},
+		{"fractional expiry", testToken(`{"exp": 1700000000.75}`), time.Unix(1700000000, 0), false},
+		{"missing expiry", testToken(`{"username": "bridge"}`), time.Time{}, true},
+		{"malformed token", "not-a-token", time.Time{}, true},
+		{"malformed payload", "header.%%%.signature", time.Time{}, true},
+		{"empty token", "", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenExpiry(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenExpiry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("tokenExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenRefreshDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name  string
+		token string
+		want  time.Duration
+	}{
+		{"refresh before expiry", testToken(`{"exp": 1700003600}`), time.Hour - JWT_REFRESH_MARGIN},
+		{"expiring soon", testToken(`{"exp": 1700000060}`), JWT_MIN_REFRESH_DELAY},
+		{"already expired", testToken(`{"exp": 1699990000}`), JWT_MIN_REFRESH_DELAY},
+		{"unknown expiry", "opaque", JWT_DEFAULT_REFRESH_DELAY},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenRefreshDelay(tt.token, now); got != tt.want {
+				t.Errorf("tokenRefreshDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketClient_RequestWhileAuthenticating(t *testing.T) {
+	client := newTestClient(1)
+	client.state = WEB_SOCKET_STATE_AUTHENTICATING
+
+	_, err := client.Request(context.Background(), "printer.info", nil)
+
+	var notConnected *ClientNotConnectedError
+	if !errors.As(err, &notConnected) {
+		t.Fatalf("Request(printer.info) error = %v, want ClientNotConnectedError", err)
+	}
+
+	go func() {
+		message := <-client.sendChan
+		client.handleMessage(&WebSocketMessage{
+			ID:     message.ID,
+			Result: map[string]any{"connection_id": 42},
+		})
+	}()
+
+	response, err := client.Request(context.Background(), "server.connection.identify", nil)
+	if err != nil {
+		t.Fatalf("Request(server.connection.identify) error = %v", err)
+	}
+	if response.Result == nil {
+		t.Error("Request(server.connection.identify) returned no result")
+	}
+}
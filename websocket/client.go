@@ -2,12 +2,14 @@ package websocket
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/websocket"
@@ -26,10 +28,15 @@ type WebSocketClient struct {
 	requestsMux  sync.RWMutex
 	nextID       int
 	sendChan     chan *WebSocketMessage
+	priorityChan chan *WebSocketMessage
 	closeChan    chan struct{}
 	dataHandlers []DataHandler
 	handlersMux  sync.RWMutex
-	retry        *Retry
+	lastSeen     atomic.Int64
+	token        string
+	refresh      string
+	refreshStop  chan struct{}
+	authMux      sync.Mutex
 	logger       logger.Logger
 }
 
@@ -41,30 +48,47 @@ func NewWebSocketClient(config *config.MoonrakerConfig, listener StatusListener,
 		state:        WEB_SOCKET_STATE_STOPPED,
 		requests:     make(map[int]*WebSocketRequest),
 		nextID:       1,
-		sendChan:     make(chan *WebSocketMessage, 100),
+		sendChan:     make(chan *WebSocketMessage, SEND_BUFFER_SIZE),
+		priorityChan: make(chan *WebSocketMessage, PRIORITY_SEND_BUFFER_SIZE),
 		closeChan:    make(chan struct{}),
 		dataHandlers: make([]DataHandler, 0),
-		retry:        NewRetry(config.AutoReconnect, config.MaxReconnectAttempts, logger),
-		logger:       logger,
+		logger:       logger.WithComponent(LOG_COMPONENT),
 	}
 }
 
 func (c *WebSocketClient) Connect(ctx context.Context) error {
-	err := c.connectOnce(ctx)
-	if err == nil {
-		c.retry.Reset()
-		return nil
+	token := ""
+	if c.currentConfig().OneshotToken {
+		var err error
+		if token, err = c.fetchOneshotToken(ctx); err != nil {
+			return err
+		}
 	}
 
-	if !c.retry.IsEnabled() {
+	if err := c.connectOnce(ctx, token); err != nil {
 		return err
 	}
 
-	go c.reconnectLoop(ctx)
+	if err := c.authenticate(ctx); err != nil {
+		if disconnectErr := c.Disconnect(); disconnectErr != nil {
+			c.logger.Debug("Failed to close unauthenticated connection: %v", disconnectErr)
+		}
+		if c.listener != nil {
+			c.listener.OnException(err)
+		}
+		return fmt.Errorf("failed to authenticate with Moonraker: %w", err)
+	}
+
+	c.stateMux.RLock()
+	closeChan := c.closeChan
+	c.stateMux.RUnlock()
+
+	go c.keepaliveLoop(closeChan)
+
 	return nil
 }
 
-func (c *WebSocketClient) connectOnce(ctx context.Context) error {
+func (c *WebSocketClient) connectOnce(ctx context.Context, token string) error {
 	c.stateMux.Lock()
 	defer c.stateMux.Unlock()
 
@@ -76,18 +100,29 @@ func (c *WebSocketClient) connectOnce(ctx context.Context) error {
 
 	wsURL := c.config.GetWebSocketURL()
 
-	url, err := url.Parse(wsURL)
-	if err != nil {
+	if _, err := url.Parse(wsURL); err != nil {
 		c.setState(WEB_SOCKET_STATE_STOPPED)
 		return fmt.Errorf("invalid WebSocket URL: %w", err)
 	}
 
-	wsConfig, err := websocket.NewConfig(wsURL, "http://"+url.Host)
+	dialURL := wsURL
+	if token != "" {
+		dialURL = fmt.Sprintf("%s?token=%s", wsURL, url.QueryEscape(token))
+	}
+
+	wsConfig, err := websocket.NewConfig(dialURL, c.config.GetHTTPURL())
 	if err != nil {
 		c.setState(WEB_SOCKET_STATE_STOPPED)
 		return fmt.Errorf("failed to create WebSocket config: %w", err)
 	}
 
+	tlsConfig, err := c.config.GetTLSConfig()
+	if err != nil {
+		c.setState(WEB_SOCKET_STATE_STOPPED)
+		return fmt.Errorf("invalid TLS config: %w", err)
+	}
+	wsConfig.TlsConfig = tlsConfig
+
 	if c.config.APIKey != "" {
 		wsConfig.Header = http.Header{}
 		wsConfig.Header.Set("X-Api-Key", c.config.APIKey)
@@ -118,49 +153,21 @@ func (c *WebSocketClient) connectOnce(ctx context.Context) error {
 		}
 
 		c.conn = res.conn
-		c.setState(WEB_SOCKET_STATE_CONNECTED)
+		c.setState(WEB_SOCKET_STATE_AUTHENTICATING)
 
-		c.sendChan = make(chan *WebSocketMessage, 100)
+		c.sendChan = make(chan *WebSocketMessage, SEND_BUFFER_SIZE)
+		c.priorityChan = make(chan *WebSocketMessage, PRIORITY_SEND_BUFFER_SIZE)
 		c.closeChan = make(chan struct{})
+		c.touch()
 
-		go c.readLoop()
-		go c.writeLoop()
+		go c.readLoop(res.conn, c.closeChan)
+		go c.writeLoop(res.conn, c.closeChan, c.sendChan, c.priorityChan)
 
 		c.logger.Info("Connected to Moonraker at %s", wsURL)
 		return nil
 	}
 }
 
-func (c *WebSocketClient) reconnectLoop(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			if !c.retry.ShouldReconnect() {
-				return
-			}
-
-			if err := c.retry.WaitBeforeReconnect(ctx); err != nil {
-				return
-			}
-
-			if ctx.Err() != nil {
-				return
-			}
-
-			err := c.connectOnce(ctx)
-			if err == nil {
-				c.logger.Info("WebSocket reconnection successful")
-				c.retry.Reset()
-				return
-			}
-
-			c.logger.Error("WebSocket reconnection failed: %v", err)
-		}
-	}
-}
-
 func (c *WebSocketClient) Disconnect() error {
 	c.stateMux.Lock()
 	defer c.stateMux.Unlock()
@@ -169,7 +176,33 @@ func (c *WebSocketClient) Disconnect() error {
 		return nil
 	}
 
+	c.closeConnection()
+	c.logger.Info("Disconnected from Moonraker")
+	return nil
+}
+
+func (c *WebSocketClient) Reconfigure(config *config.MoonrakerConfig) error {
+	if err := c.Disconnect(); err != nil {
+		return err
+	}
+
+	c.stateMux.Lock()
+	c.config = config
+	c.stateMux.Unlock()
+
+	c.setTokens("", "")
+	return nil
+}
+
+func (c *WebSocketClient) currentConfig() *config.MoonrakerConfig {
+	c.stateMux.RLock()
+	defer c.stateMux.RUnlock()
+	return c.config
+}
+
+func (c *WebSocketClient) closeConnection() {
 	c.setState(WEB_SOCKET_STATE_STOPPING)
+	c.stopTokenRefresh()
 
 	select {
 	case <-c.closeChan:
@@ -185,8 +218,68 @@ func (c *WebSocketClient) Disconnect() error {
 	}
 
 	c.setState(WEB_SOCKET_STATE_STOPPED)
-	c.logger.Info("Disconnected from Moonraker")
-	return nil
+}
+
+func (c *WebSocketClient) dropConnection(closeChan chan struct{}, reason error) {
+	c.stateMux.Lock()
+	if c.closeChan != closeChan || c.state == WEB_SOCKET_STATE_STOPPING || c.state == WEB_SOCKET_STATE_STOPPED {
+		c.stateMux.Unlock()
+		return
+	}
+	c.closeConnection()
+	c.stateMux.Unlock()
+
+	if reason != nil && c.listener != nil {
+		c.listener.OnException(reason)
+	}
+}
+
+func (c *WebSocketClient) keepaliveLoop(closeChan chan struct{}) {
+	cfg := c.currentConfig()
+	interval := cfg.GetKeepaliveInterval()
+	if interval <= 0 {
+		return
+	}
+	liveness := cfg.GetLivenessTimeout()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closeChan:
+			return
+		case <-ticker.C:
+			idle := time.Since(c.LastSeen())
+			if idle >= liveness {
+				c.logger.Warn("No data received from Moonraker for %s, closing stale connection", idle.Round(time.Second))
+				c.dropConnection(closeChan, NewWebSocketError("connection stale", fmt.Errorf("no data received for %s", idle.Round(time.Second))))
+				return
+			}
+
+			if idle >= interval {
+				go c.probe(liveness - idle)
+			}
+		}
+	}
+}
+
+func (c *WebSocketClient) probe(timeout time.Duration) {
+	if _, err := c.sendRequest(context.Background(), "server.info", nil, timeout); err != nil {
+		c.logger.Debug("Keepalive probe failed: %v", err)
+	}
+}
+
+func (c *WebSocketClient) touch() {
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+func (c *WebSocketClient) LastSeen() time.Time {
+	lastSeen := c.lastSeen.Load()
+	if lastSeen == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, lastSeen)
 }
 
 func (c *WebSocketClient) GetState() string {
@@ -195,6 +288,12 @@ func (c *WebSocketClient) GetState() string {
 	return c.state
 }
 
+func (c *WebSocketClient) sendChannels() (string, chan *WebSocketMessage, chan *WebSocketMessage) {
+	c.stateMux.RLock()
+	defer c.stateMux.RUnlock()
+	return c.state, c.sendChan, c.priorityChan
+}
+
 func (c *WebSocketClient) IsConnected() bool {
 	return c.GetState() == WEB_SOCKET_STATE_CONNECTED
 }
@@ -216,11 +315,15 @@ func (c *WebSocketClient) UnregisterDataHandler(handler DataHandler) {
 }
 
 func (c *WebSocketClient) Request(ctx context.Context, method string, params any) (*WebSocketResponse, error) {
+	return c.RequestWithTimeout(ctx, method, params, 0)
+}
+
+func (c *WebSocketClient) RequestWithTimeout(ctx context.Context, method string, params any, timeout time.Duration) (*WebSocketResponse, error) {
 	if ctx.Err() != nil {
 		return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
 	}
 
-	msg, err := c.SendRequest(method, params)
+	msg, err := c.sendRequest(ctx, method, params, timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +353,7 @@ func (c *WebSocketClient) removeDataHandler(handler DataHandler) {
 	}
 }
 
-func (c *WebSocketClient) readLoop() {
+func (c *WebSocketClient) readLoop(conn *websocket.Conn, closeChan chan struct{}) {
 	defer func() {
 		if r := recover(); r != nil {
 			c.logger.Error("Read loop panic: %v", r)
@@ -259,34 +362,36 @@ func (c *WebSocketClient) readLoop() {
 
 	for {
 		select {
-		case <-c.closeChan:
+		case <-closeChan:
 			return
 		default:
 			var message WebSocketMessage
-			err := websocket.JSON.Receive(c.conn, &message)
+			err := websocket.JSON.Receive(conn, &message)
 			if err != nil {
+				select {
+				case <-closeChan:
+					c.logger.Debug("Read error during shutdown (expected): %v", err)
+					return
+				default:
+				}
+
 				if err == io.EOF {
 					c.logger.Info("Connection closed by server")
+					c.dropConnection(closeChan, nil)
 				} else {
-					if c.GetState() == WEB_SOCKET_STATE_STOPPING || c.GetState() == WEB_SOCKET_STATE_STOPPED {
-						c.logger.Debug("Read error during shutdown (expected): %v", err)
-					} else {
-						c.logger.Error("Read error: %v, State: %s", err, c.GetState())
-						if c.listener != nil {
-							c.listener.OnException(NewWebSocketError("read error", err))
-						}
-					}
+					c.logger.Error("Read error: %v, State: %s", err, c.GetState())
+					c.dropConnection(closeChan, NewWebSocketError("read error", err))
 				}
-				c.setState(WEB_SOCKET_STATE_STOPPED)
 				return
 			}
 
+			c.touch()
 			c.handleMessage(&message)
 		}
 	}
 }
 
-func (c *WebSocketClient) writeLoop() {
+func (c *WebSocketClient) writeLoop(conn *websocket.Conn, closeChan chan struct{}, sendChan, priorityChan chan *WebSocketMessage) {
 	defer func() {
 		if r := recover(); r != nil {
 			c.logger.Error("Write loop panic: %v", r)
@@ -294,20 +399,27 @@ func (c *WebSocketClient) writeLoop() {
 	}()
 
 	for {
+		var message *WebSocketMessage
+
 		select {
-		case <-c.closeChan:
+		case <-closeChan:
 			return
-		case message := <-c.sendChan:
-			err := websocket.JSON.Send(c.conn, message)
-			if err != nil {
-				c.logger.Error("Write error: %v", err)
-				c.setState(WEB_SOCKET_STATE_STOPPED)
-				if c.listener != nil {
-					c.listener.OnException(NewWebSocketError("write error", err))
-				}
+		case message = <-priorityChan:
+		default:
+			select {
+			case <-closeChan:
 				return
+			case message = <-priorityChan:
+			case message = <-sendChan:
 			}
 		}
+
+		err := websocket.JSON.Send(conn, message)
+		if err != nil {
+			c.logger.Error("Write error: %v", err)
+			c.dropConnection(closeChan, NewWebSocketError("write error", err))
+			return
+		}
 	}
 }
 
@@ -348,14 +460,40 @@ func (c *WebSocketClient) handleMessage(message *WebSocketMessage) {
 }
 
 func (c *WebSocketClient) SendRequest(method string, params any) (*WebSocketMessage, error) {
-	return c.SendRequestWithTimeout(method, params, 30*time.Second)
+	return c.sendRequest(context.Background(), method, params, 0)
 }
 
 func (c *WebSocketClient) SendRequestWithTimeout(method string, params any, timeout time.Duration) (*WebSocketMessage, error) {
-	if c.GetState() != WEB_SOCKET_STATE_CONNECTED {
+	return c.sendRequest(context.Background(), method, params, timeout)
+}
+
+func (c *WebSocketClient) requestTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	deadline, hasDeadline := ctx.Deadline()
+
+	if timeout <= 0 {
+		if hasDeadline {
+			return time.Until(deadline)
+		}
+		return c.currentConfig().GetTimeout()
+	}
+
+	if hasDeadline && time.Until(deadline) < timeout {
+		return time.Until(deadline)
+	}
+
+	return timeout
+}
+
+func (c *WebSocketClient) sendRequest(ctx context.Context, method string, params any, timeout time.Duration) (*WebSocketMessage, error) {
+	state, sendChan, priorityChan := c.sendChannels()
+	if state != WEB_SOCKET_STATE_CONNECTED && !(state == WEB_SOCKET_STATE_AUTHENTICATING && authMethods[method]) {
 		return nil, NewWebSocketNotConnectedError("not connected")
 	}
 
+	timeout = c.requestTimeout(ctx, timeout)
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	c.requestsMux.Lock()
 	id := c.nextID
 	c.nextID++
@@ -375,13 +513,20 @@ func (c *WebSocketClient) SendRequestWithTimeout(method string, params any, time
 		Params:  params,
 		JSONRPC: "2.0",
 	}
-	select {
-	case c.sendChan <- message:
-	default:
-		c.requestsMux.Lock()
-		delete(c.requests, id)
-		c.requestsMux.Unlock()
-		return nil, NewWebSocketError("send buffer full", nil)
+	if priorityMethods[method] {
+		select {
+		case priorityChan <- message:
+		case <-requestCtx.Done():
+			c.removeRequest(id)
+			return nil, NewWebSocketError("priority send buffer full", requestCtx.Err())
+		}
+	} else {
+		select {
+		case sendChan <- message:
+		default:
+			c.removeRequest(id)
+			return nil, NewWebSocketError("send buffer full", nil)
+		}
 	}
 
 	select {
@@ -393,14 +538,29 @@ func (c *WebSocketClient) SendRequestWithTimeout(method string, params any, time
 			Result:  response.Result,
 			JSONRPC: "2.0",
 		}, nil
-	case <-time.After(timeout):
-		c.requestsMux.Lock()
-		delete(c.requests, id)
-		c.requestsMux.Unlock()
-		return nil, NewWebSocketTimeoutError("request timeout")
+	case <-requestCtx.Done():
+		c.removeRequest(id)
+		if errors.Is(requestCtx.Err(), context.DeadlineExceeded) {
+			return nil, NewRequestTimeoutError(method, timeout)
+		}
+		return nil, fmt.Errorf("request %s cancelled: %w", method, requestCtx.Err())
 	}
 }
 
+func (c *WebSocketClient) removeRequest(id int) {
+	c.requestsMux.Lock()
+	defer c.requestsMux.Unlock()
+
+	delete(c.requests, id)
+}
+
+func (c *WebSocketClient) PendingRequests() int {
+	c.requestsMux.RLock()
+	defer c.requestsMux.RUnlock()
+
+	return len(c.requests)
+}
+
 func (c *WebSocketClient) Subscribe(objects map[string]any) error {
 	_, err := c.SendRequest("server.websocket.subscribe", map[string]any{
 		"objects": objects,
@@ -409,7 +569,8 @@ func (c *WebSocketClient) Subscribe(objects map[string]any) error {
 }
 
 func (c *WebSocketClient) SendNotification(method string, params any) error {
-	if c.GetState() != WEB_SOCKET_STATE_CONNECTED {
+	state, sendChan, _ := c.sendChannels()
+	if state != WEB_SOCKET_STATE_CONNECTED {
 		return NewWebSocketNotConnectedError("not connected")
 	}
 
@@ -420,7 +581,7 @@ func (c *WebSocketClient) SendNotification(method string, params any) error {
 	}
 
 	select {
-	case c.sendChan <- message:
+	case sendChan <- message:
 		return nil
 	default:
 		return NewWebSocketError("send buffer full", nil)
@@ -0,0 +1,251 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"moonraker2mqtt/config"
+	"moonraker2mqtt/logger"
+)
+
+type testLogger struct{}
+
+func (testLogger) Debug(format string, args ...any)               {}
+func (testLogger) Info(format string, args ...any)                {}
+func (testLogger) Warn(format string, args ...any)                {}
+func (testLogger) Error(format string, args ...any)               {}
+func (testLogger) SetLevel(level logger.LogLevel)                 {}
+func (testLogger) GetLevel() logger.LogLevel                      { return logger.DEBUG }
+func (l testLogger) With(fields ...any) logger.Logger             { return l }
+func (l testLogger) WithComponent(component string) logger.Logger { return l }
+
+type testListener struct {
+	states     chan string
+	exceptions chan error
+}
+
+func (l *testListener) OnStateChanged(state string)              { l.states <- state }
+func (l *testListener) OnNotification(method string, params any) {}
+func (l *testListener) OnException(err error)                    { l.exceptions <- err }
+
+func newTestClient(timeout int) *WebSocketClient {
+	return &WebSocketClient{
+		config:       &config.MoonrakerConfig{Timeout: timeout},
+		state:        WEB_SOCKET_STATE_CONNECTED,
+		requests:     make(map[int]*WebSocketRequest),
+		nextID:       1,
+		sendChan:     make(chan *WebSocketMessage, 10),
+		priorityChan: make(chan *WebSocketMessage, PRIORITY_SEND_BUFFER_SIZE),
+	}
+}
+
+func TestWebSocketClient_RequestTimeout(t *testing.T) {
+	client := newTestClient(30)
+
+	start := time.Now()
+	_, err := client.RequestWithTimeout(context.Background(), "printer.gcode.script", nil, 50*time.Millisecond)
+
+	var timeoutErr *RequestTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("RequestWithTimeout() error = %v, want RequestTimeoutError", err)
+	}
+	if timeoutErr.Timeout() != 50*time.Millisecond {
+		t.Errorf("Timeout() = %v, want 50ms", timeoutErr.Timeout())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RequestWithTimeout() returned after %v, want about 50ms", elapsed)
+	}
+	if pending := client.PendingRequests(); pending != 0 {
+		t.Errorf("PendingRequests() = %d after timeout, want 0", pending)
+	}
+}
+
+func TestWebSocketClient_RequestContextDeadline(t *testing.T) {
+	client := newTestClient(30)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Request(ctx, "printer.info", nil)
+
+	var timeoutErr *RequestTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Request() error = %v, want RequestTimeoutError", err)
+	}
+	if timeoutErr.Timeout() > 50*time.Millisecond {
+		t.Errorf("Timeout() = %v, want at most 50ms", timeoutErr.Timeout())
+	}
+}
+
+func TestWebSocketClient_RequestCancelled(t *testing.T) {
+	client := newTestClient(30)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := client.Request(ctx, "printer.info", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Request() error = %v, want context.Canceled", err)
+	}
+	if pending := client.PendingRequests(); pending != 0 {
+		t.Errorf("PendingRequests() = %d after cancellation, want 0", pending)
+	}
+}
+
+func TestWebSocketClient_RequestResponse(t *testing.T) {
+	client := newTestClient(1)
+
+	go func() {
+		message := <-client.sendChan
+		client.handleMessage(&WebSocketMessage{ID: message.ID, Result: "ok"})
+	}()
+
+	response, err := client.Request(context.Background(), "printer.gcode.script", nil)
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if response.Result != "ok" {
+		t.Errorf("Request() result = %v, want ok", response.Result)
+	}
+}
+
+func TestWebSocketClient_PriorityRequestBypassesFullQueue(t *testing.T) {
+	client := newTestClient(1)
+	client.sendChan = make(chan *WebSocketMessage, 1)
+	client.sendChan <- &WebSocketMessage{Method: "printer.objects.query"}
+
+	if _, err := client.RequestWithTimeout(context.Background(), "printer.objects.query", nil, 50*time.Millisecond); err == nil {
+		t.Fatal("Request() expected send buffer full error")
+	}
+
+	go func() {
+		message := <-client.priorityChan
+		client.handleMessage(&WebSocketMessage{ID: message.ID, Result: "ok"})
+	}()
+
+	response, err := client.Request(context.Background(), "printer.emergency_stop", nil)
+	if err != nil {
+		t.Fatalf("Request(printer.emergency_stop) error = %v", err)
+	}
+	if response.Result != "ok" {
+		t.Errorf("Request(printer.emergency_stop) result = %v, want ok", response.Result)
+	}
+}
+
+func TestWebSocketClient_KeepaliveClosesStaleConnection(t *testing.T) {
+	listener := &testListener{
+		states:     make(chan string, 10),
+		exceptions: make(chan error, 10),
+	}
+
+	client := newTestClient(1)
+	client.config.KeepaliveInterval = 1
+	client.config.LivenessTimeout = 2
+	client.listener = listener
+	client.logger = testLogger{}
+	client.closeChan = make(chan struct{})
+	client.lastSeen.Store(time.Now().Add(-time.Minute).UnixNano())
+
+	done := make(chan struct{})
+	go func() {
+		client.keepaliveLoop(client.closeChan)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("keepaliveLoop() did not close the stale connection")
+	}
+
+	if state := client.GetState(); state != WEB_SOCKET_STATE_STOPPED {
+		t.Errorf("GetState() = %s after stale connection, want %s", state, WEB_SOCKET_STATE_STOPPED)
+	}
+
+	select {
+	case err := <-listener.exceptions:
+		var wsErr *WebSocketError
+		if !errors.As(err, &wsErr) {
+			t.Errorf("OnException() error = %v, want WebSocketError", err)
+		}
+	default:
+		t.Error("OnException() was not called for the stale connection")
+	}
+}
+
+func TestWebSocketClient_KeepaliveProbesIdleConnection(t *testing.T) {
+	client := newTestClient(1)
+	client.config.KeepaliveInterval = 1
+	client.config.LivenessTimeout = 10
+	client.logger = testLogger{}
+	client.closeChan = make(chan struct{})
+	client.lastSeen.Store(time.Now().Add(-2 * time.Second).UnixNano())
+
+	go client.keepaliveLoop(client.closeChan)
+	defer close(client.closeChan)
+
+	select {
+	case message := <-client.sendChan:
+		if message.Method != "server.info" {
+			t.Errorf("keepalive probe method = %s, want server.info", message.Method)
+		}
+		client.handleMessage(&WebSocketMessage{ID: message.ID, Result: map[string]any{}})
+	case <-time.After(3 * time.Second):
+		t.Fatal("keepaliveLoop() did not probe the idle connection")
+	}
+
+	if client.GetState() != WEB_SOCKET_STATE_CONNECTED {
+		t.Errorf("GetState() = %s, want %s", client.GetState(), WEB_SOCKET_STATE_CONNECTED)
+	}
+}
+
+func TestWebSocketClient_ReconfigureWhileRequesting(t *testing.T) {
+	client := newTestClient(30)
+	client.logger = testLogger{}
+	client.closeChan = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			client.requestTimeout(context.Background(), 0)
+		}
+	}()
+
+	for i := 1; i <= 100; i++ {
+		if err := client.Reconfigure(&config.MoonrakerConfig{Timeout: i}); err != nil {
+			t.Fatalf("Reconfigure() error = %v", err)
+		}
+	}
+	<-done
+
+	if timeout := client.requestTimeout(context.Background(), 0); timeout != 100*time.Second {
+		t.Errorf("requestTimeout() = %v after Reconfigure, want 100s", timeout)
+	}
+}
+
+func TestWebSocketClient_SendWhileReconnecting(t *testing.T) {
+	client := newTestClient(30)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			client.stateMux.Lock()
+			client.sendChan = make(chan *WebSocketMessage, 10)
+			client.priorityChan = make(chan *WebSocketMessage, PRIORITY_SEND_BUFFER_SIZE)
+			client.stateMux.Unlock()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		_ = client.SendNotification("notify_test", nil)
+		_, _ = client.RequestWithTimeout(context.Background(), "printer.emergency_stop", nil, time.Millisecond)
+	}
+	<-done
+}
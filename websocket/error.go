@@ -1,6 +1,9 @@
 package websocket
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type (
 	ClientNotConnectedError struct {
@@ -16,8 +19,8 @@ type (
 	}
 
 	RequestTimeoutError struct {
-		message string
-		timeout int
+		method  string
+		timeout time.Duration
 	}
 
 	WebSocketError struct {
@@ -48,10 +51,14 @@ func (e *ClientNotAuthenticatedError) Error() string {
 }
 
 func (e *RequestTimeoutError) Error() string {
-	if e.message != "" {
-		return e.message
+	if e.method != "" {
+		return fmt.Sprintf("request %s timed out after %s", e.method, e.timeout)
 	}
-	return fmt.Sprintf("request timed out after %d seconds", e.timeout)
+	return fmt.Sprintf("request timed out after %s", e.timeout)
+}
+
+func (e *RequestTimeoutError) Timeout() time.Duration {
+	return e.timeout
 }
 
 func (e *WebSocketError) Error() string {
@@ -73,8 +80,8 @@ func NewClientNotAuthenticatedError(message string) *ClientNotAuthenticatedError
 	return &ClientNotAuthenticatedError{message: message}
 }
 
-func NewRequestTimeoutError(message string, timeout int) *RequestTimeoutError {
-	return &RequestTimeoutError{message: message, timeout: timeout}
+func NewRequestTimeoutError(method string, timeout time.Duration) *RequestTimeoutError {
+	return &RequestTimeoutError{method: method, timeout: timeout.Round(time.Millisecond)}
 }
 
 func NewWebSocketError(message string, err error) *WebSocketError {
@@ -84,7 +91,3 @@ func NewWebSocketError(message string, err error) *WebSocketError {
 func NewWebSocketNotConnectedError(message string) error {
 	return &ClientNotConnectedError{message: message}
 }
-
-func NewWebSocketTimeoutError(message string) error {
-	return &RequestTimeoutError{message: message, timeout: 30}
-}
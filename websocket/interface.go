@@ -2,6 +2,9 @@ package websocket
 
 import (
 	"context"
+	"time"
+
+	"moonraker2mqtt/config"
 )
 
 type StatusListener interface {
@@ -17,9 +20,12 @@ type DataHandler interface {
 type Client interface {
 	Connect(ctx context.Context) error
 	Disconnect() error
+	Reconfigure(config *config.MoonrakerConfig) error
 	IsConnected() bool
 	GetState() string
+	LastSeen() time.Time
 	Request(ctx context.Context, method string, params any) (*WebSocketResponse, error)
+	RequestWithTimeout(ctx context.Context, method string, params any, timeout time.Duration) (*WebSocketResponse, error)
 	RegisterDataHandler(handler DataHandler)
 	UnregisterDataHandler(handler DataHandler)
 }
@@ -1,20 +1,46 @@
 package websocket
 
 import (
-	"moonraker2mqtt/logger"
 	"time"
 )
 
 const (
-	WEB_SOCKET_STATE_CONNECTING = "ws_connecting"
-	WEB_SOCKET_STATE_CONNECTED  = "ws_connected"
-	WEB_SOCKET_STATE_STOPPING   = "ws_stopping"
-	WEB_SOCKET_STATE_STOPPED    = "ws_stopped"
-	INITIAL_RETRY_DELAY         = 1
-	MAX_RETRY_DELAY             = 60
-	RETRY_BACKOFF_MULTIPLIER    = 2
+	LOG_COMPONENT = "websocket"
+
+	WEB_SOCKET_STATE_CONNECTING     = "ws_connecting"
+	WEB_SOCKET_STATE_AUTHENTICATING = "ws_authenticating"
+	WEB_SOCKET_STATE_CONNECTED      = "ws_connected"
+	WEB_SOCKET_STATE_STOPPING       = "ws_stopping"
+	WEB_SOCKET_STATE_STOPPED        = "ws_stopped"
+	SEND_BUFFER_SIZE                = 100
+	PRIORITY_SEND_BUFFER_SIZE       = 8
+	RPC_METHOD_NOT_FOUND            = -32601
+	CLIENT_TYPE_OTHER               = "other"
+	PROJECT_URL                     = "https://github.com/AC-CodeProd/moonraker2mqtt"
+	JWT_REFRESH_MARGIN              = 5 * time.Minute
+	JWT_MIN_REFRESH_DELAY           = 30 * time.Second
+	JWT_DEFAULT_REFRESH_DELAY       = 30 * time.Minute
 )
 
+var priorityMethods = map[string]bool{
+	"printer.emergency_stop": true,
+	"printer.print.cancel":   true,
+}
+
+var authMethods = map[string]bool{
+	"access.login":               true,
+	"access.refresh_jwt":         true,
+	"access.oneshot_token":       true,
+	"server.connection.identify": true,
+}
+
+type AuthTokens struct {
+	Username     string `json:"username"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	Source       string `json:"source"`
+}
+
 type WebSocketMessage struct {
 	JSONRPC string    `json:"jsonrpc"`
 	Method  string    `json:"method,omitempty"`
@@ -71,10 +97,18 @@ type PrinterInfo struct {
 	Objects         map[string]any `json:"objects"`
 }
 
-type Retry struct {
-	enabled      bool
-	maxAttempts  int
-	currentDelay time.Duration
-	attempt      int
-	logger       logger.Logger
+type FileMetadata struct {
+	Filename         string  `json:"filename"`
+	Size             int64   `json:"size"`
+	Modified         float64 `json:"modified"`
+	Slicer           string  `json:"slicer"`
+	SlicerVersion    string  `json:"slicer_version"`
+	EstimatedTime    float64 `json:"estimated_time"`
+	FilamentTotal    float64 `json:"filament_total"`
+	GcodeStartByte   int64   `json:"gcode_start_byte"`
+	GcodeEndByte     int64   `json:"gcode_end_byte"`
+	LayerCount       int     `json:"layer_count"`
+	LayerHeight      float64 `json:"layer_height"`
+	FirstLayerHeight float64 `json:"first_layer_height"`
+	ObjectHeight     float64 `json:"object_height"`
 }